@@ -15,25 +15,115 @@ func Encode(params []*Parameter, values []interface{}) ([]byte, error) {
 	var rlpCompatibleArgs []interface{}
 
 	for index, param := range params {
-		if param.IsArray {
-			arrayArg, err := param.Type.NewArrayArgument(values[index])
+		argument, err := encodeArgument(param, values[index])
+		if err != nil {
+			return nil, err
+		}
+		rlpCompatibleArgs = append(rlpCompatibleArgs, argument)
+	}
+	result, err := rlp.EncodeToBytes(rlpCompatibleArgs)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// encodeArgument returns vm-compatible bytes of value of param
+func encodeArgument(param *Parameter, value interface{}) ([]byte, error) {
+	if param.Type == Tuple {
+		return encodeTupleArgument(param, value)
+	}
+	var argument []byte
+	var err error
+	if param.IsArray {
+		argument, err = param.Type.NewArrayArgument(value)
+	} else {
+		argument, err = param.Type.NewArgument(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if param.Size > 0 && len(argument) != param.GetMemorySize() {
+		return nil, fmt.Errorf("Argument %s size mismatch, expecting: %d, got: %d", param.Name, param.GetMemorySize(), len(argument))
+	}
+	return argument, nil
+}
+
+// encodeTupleArgument encodes a tuple as RLP list of its encoded fields,
+// and an array of tuples as RLP list of its encoded tuples.
+// A tuple value is either a slice of field values in order or a map of field name to value
+func encodeTupleArgument(param *Parameter, value interface{}) ([]byte, error) {
+	s := param.Struct()
+	if s == nil {
+		return nil, fmt.Errorf("Argument %s has no struct", param.Name)
+	}
+	if param.IsArray {
+		elements, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unable to convert array element into %s", param.TypeName())
+		}
+		if param.Size > 0 && len(elements) != int(param.Size) {
+			return nil, fmt.Errorf("Argument %s size mismatch, expecting: %d, got: %d", param.Name, param.Size, len(elements))
+		}
+		encoded := [][]byte{}
+		for _, element := range elements {
+			tuple, err := encodeTuple(s, element)
 			if err != nil {
 				return nil, err
 			}
-			rlpCompatibleArgs = append(rlpCompatibleArgs, arrayArg)
-		} else {
-			argument, err := param.Type.NewArgument(values[index])
-			if err != nil {
-				return nil, err
+			encoded = append(encoded, tuple)
+		}
+		return rlp.EncodeToBytes(encoded)
+	}
+	return encodeTuple(s, value)
+}
+
+func encodeTuple(s *Struct, value interface{}) ([]byte, error) {
+	var fieldValues []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		fieldValues = v
+	case map[string]interface{}:
+		for _, field := range s.Fields {
+			fieldValue, ok := v[field.Name]
+			if !ok {
+				return nil, fmt.Errorf("Field %s of %s not found", field.Name, s.Name)
 			}
-			rlpCompatibleArgs = append(rlpCompatibleArgs, argument)
+			fieldValues = append(fieldValues, fieldValue)
 		}
+	default:
+		return nil, fmt.Errorf("unable to convert value into tuple %s", s.Name)
 	}
-	result, err := rlp.EncodeToBytes(rlpCompatibleArgs)
-	if err != nil {
+	if len(fieldValues) != len(s.Fields) {
+		return nil, fmt.Errorf("Field count mismatch, expecting: %d, got: %d", len(s.Fields), len(fieldValues))
+	}
+
+	fields := [][]byte{}
+	for i, field := range s.Fields {
+		encoded, err := encodeArgument(field, fieldValues[i])
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, encoded)
+	}
+	return rlp.EncodeToBytes(fields)
+}
+
+// DecodeTuple returns encoded fields of a tuple argument
+func DecodeTuple(s *Struct, bytes []byte) ([][]byte, error) {
+	return DecodeToBytes(s.Fields, bytes)
+}
+
+// DecodeTupleArray returns encoded tuples of an array of tuples argument
+func DecodeTupleArray(param *Parameter, bytes []byte) ([][]byte, error) {
+	var decoded [][]byte
+	if err := rlp.DecodeBytes(bytes, &decoded); err != nil {
 		return nil, err
 	}
-	return result, nil
+	if param.Size > 0 && len(decoded) != int(param.Size) {
+		return nil, fmt.Errorf("Argument %s size mismatch, expecting: %d, got: %d", param.Name, param.Size, len(decoded))
+	}
+	return decoded, nil
 }
 
 // DecodeToBytes returns uint64 array compatible with VM
@@ -274,3 +274,18 @@ func TestBytesEncoding(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeFixedSize(t *testing.T) {
+	params := []*Parameter{{Name: "hash", Type: Bytes, Size: 4}, {Name: "values", IsArray: true, Type: Uint8, Size: 2}}
+	if _, err := Encode(params, []interface{}{[]byte{1, 2, 3, 4}, []uint8{1, 2}}); err != nil {
+		t.Error(err)
+	}
+	_, err := Encode(params, []interface{}{[]byte{1, 2, 3}, []uint8{1, 2}})
+	if err == nil || err.Error() != "Argument hash size mismatch, expecting: 4, got: 3" {
+		t.Errorf("Expect size mismatch error, got %v", err)
+	}
+	_, err = Encode(params, []interface{}{[]byte{1, 2, 3, 4}, []uint8{1}})
+	if err == nil || err.Error() != "Argument values size mismatch, expecting: 2, got: 1" {
+		t.Errorf("Expect size mismatch error, got %v", err)
+	}
+}
@@ -2,39 +2,122 @@ package abi
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
 )
 
+// maxFixedBytesSize is the largest N of bytesN type
+const maxFixedBytesSize = 32
+
 // ParameterFile ParameterFile
 type ParameterFile struct {
 	Name string `json:"name"`
 	Type string `json:"type"`
+	// Fields are fields of an anonymous tuple type
+	Fields []ParameterFile `json:"fields,omitempty"`
 }
 
 // HeaderFile representation of Header file
 type HeaderFile struct {
 	Version uint16 `json:"version"`
-	Events  []struct {
+	Structs []struct {
+		Name   string          `json:"name"`
+		Fields []ParameterFile `json:"fields"`
+	} `json:"structs"`
+	Events []struct {
 		Name       string          `json:"name"`
 		Parameters []ParameterFile `json:"parameters"`
 	} `json:"events"`
 	Functions []struct {
 		Name       string          `json:"name"`
 		Parameters []ParameterFile `json:"parameters"`
+		Returns    []ParameterFile `json:"returns"`
 	}
 }
 
-// IsArray returns true if ParameterFile type ends in [] (an array)
+// IsArray returns true if ParameterFile type ends in [] or [N] (an array)
 func (p ParameterFile) IsArray() bool {
-	return strings.HasSuffix(p.Type, "[]")
+	return strings.HasSuffix(p.Type, "]")
+}
+
+// parseParameterFile parses a parameter, which type is primitive, tuple or one of structs
+func parseParameterFile(hParam ParameterFile, structs map[string]*Struct) (*Parameter, error) {
+	var parameter Parameter
+	pType := hParam.Type
+	parameter.IsArray = hParam.IsArray()
+	if parameter.IsArray {
+		open := strings.LastIndex(pType, "[")
+		if open < 0 {
+			return nil, fmt.Errorf("wrong array type format: %s", hParam.Type)
+		}
+		if size := pType[open+1 : len(pType)-1]; len(size) > 0 {
+			arraySize, err := strconv.ParseUint(size, 10, 32)
+			if err != nil || arraySize == 0 {
+				return nil, fmt.Errorf("wrong array size of type: %s", hParam.Type)
+			}
+			parameter.Size = uint(arraySize)
+		}
+		pType = pType[:open]
+	}
+	parameter.Name = hParam.Name
+	if s, err := parseStructType(pType, hParam.Fields, structs); err != nil {
+		return nil, err
+	} else if s != nil {
+		parameter.Type = Tuple
+		parameter.Tuple = []*Struct{s}
+		return &parameter, nil
+	}
+	if !parameter.IsArray && strings.HasPrefix(pType, "bytes") && len(pType) > len("bytes") {
+		bytesSize, err := strconv.ParseUint(pType[len("bytes"):], 10, 8)
+		if err != nil || bytesSize == 0 || bytesSize > maxFixedBytesSize {
+			return nil, fmt.Errorf("not supported type: %s for parseParameterFile", hParam.Type)
+		}
+		parameter.Size = uint(bytesSize)
+		pType = "bytes"
+	}
+	paramType, err := parsePrimitiveTypeFromString(pType)
+	if err != nil {
+		return nil, err
+	}
+	if parameter.IsArray && paramType.IsDynamic() {
+		return nil, fmt.Errorf("not supported array of type: %s", paramType)
+	}
+	parameter.Type = paramType
+	return &parameter, nil
+}
+
+// parseStructType returns struct of a tuple or named struct type, nil for other types
+func parseStructType(t string, fields []ParameterFile, structs map[string]*Struct) (*Struct, error) {
+	if s, ok := structs[t]; ok {
+		return s, nil
+	}
+	if t != Tuple.String() {
+		return nil, nil
+	}
+	return parseStruct("", fields, structs)
+}
+
+func parseStruct(name string, hFields []ParameterFile, structs map[string]*Struct) (*Struct, error) {
+	if len(hFields) == 0 {
+		return nil, fmt.Errorf("struct %s has no fields", name)
+	}
+	s := &Struct{Name: name, Fields: []*Parameter{}}
+	for _, hField := range hFields {
+		field, err := parseParameterFile(hField, structs)
+		if err != nil {
+			return nil, err
+		}
+		s.Fields = append(s.Fields, field)
+	}
+	return s, nil
 }
 
 func parsePrimitiveTypeFromString(t string) (PrimitiveType, error) {
@@ -62,6 +145,20 @@ func parsePrimitiveTypeFromString(t string) (PrimitiveType, error) {
 		primitiveType = Float32
 	case "float64":
 		primitiveType = Float64
+	case "bool":
+		primitiveType = Bool
+	case "uint128":
+		primitiveType = Uint128
+	case "uint256":
+		primitiveType = Uint256
+	case "int128":
+		primitiveType = Int128
+	case "int256":
+		primitiveType = Int256
+	case "bytes":
+		primitiveType = Bytes
+	case "string":
+		primitiveType = String
 	default:
 		return primitiveType, fmt.Errorf("not supported type: %s for parsePrimitiveTypeFromString", t)
 	}
@@ -186,6 +283,26 @@ func parseArrayArgsFromString(t PrimitiveType, value string) (interface{}, error
 			slices = append(slices, result.(float64))
 		}
 		return slices, nil
+	case Bool:
+		slices := []bool{}
+		for _, arg := range args {
+			result, err := parseArgFromString(t, arg)
+			if err != nil {
+				return nil, err
+			}
+			slices = append(slices, result.(bool))
+		}
+		return slices, nil
+	case Uint128, Uint256, Int128, Int256:
+		slices := []*big.Int{}
+		for _, arg := range args {
+			result, err := parseArgFromString(t, arg)
+			if err != nil {
+				return nil, err
+			}
+			slices = append(slices, result.(*big.Int))
+		}
+		return slices, nil
 	default:
 		return nil, fmt.Errorf("not supported type: %s", t)
 	}
@@ -193,6 +310,9 @@ func parseArrayArgsFromString(t PrimitiveType, value string) (interface{}, error
 
 func parseArgFromString(t PrimitiveType, value string) (interface{}, error) {
 	var result interface{}
+	if t == String {
+		return value, nil
+	}
 	value = strings.TrimSpace(value)
 	switch t {
 	case Address:
@@ -257,12 +377,105 @@ func parseArgFromString(t PrimitiveType, value string) (interface{}, error) {
 			return nil, err
 		}
 		result = float64(param)
+	case Bool:
+		param, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		result = param
+	case Uint128, Uint256, Int128, Int256:
+		param, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s value: %s", t, value)
+		}
+		result = param
+	case Bytes:
+		param, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		result = param
 	default:
 		return nil, fmt.Errorf("not supported type: %s", t)
 	}
 	return result, nil
 }
 
+// parseTupleArgFromString parses JSON value of a tuple or an array of tuples.
+// A tuple is written as an array of field values in order or an object of field name to value
+func parseTupleArgFromString(param *Parameter, value string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("wrong tuple value format, expected JSON, got: %s", value)
+	}
+	return parseJSONArg(param, decoded)
+}
+
+func parseJSONArg(param *Parameter, value interface{}) (interface{}, error) {
+	if param.Type == Tuple {
+		if param.IsArray {
+			elements, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("wrong array value format of %s", param.Name)
+			}
+			tuples := []interface{}{}
+			for _, element := range elements {
+				tuple, err := parseJSONTuple(param.Struct(), element)
+				if err != nil {
+					return nil, err
+				}
+				tuples = append(tuples, tuple)
+			}
+			return tuples, nil
+		}
+		return parseJSONTuple(param.Struct(), value)
+	}
+	if param.IsArray {
+		elements, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("wrong array value format of %s", param.Name)
+		}
+		args := []string{}
+		for _, element := range elements {
+			args = append(args, fmt.Sprint(element))
+		}
+		return parseArrayArgsFromString(param.Type, "["+strings.Join(args, ",")+"]")
+	}
+	return parseArgFromString(param.Type, fmt.Sprint(value))
+}
+
+func parseJSONTuple(s *Struct, value interface{}) (interface{}, error) {
+	var fieldValues []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		fieldValues = v
+	case map[string]interface{}:
+		for _, field := range s.Fields {
+			fieldValues = append(fieldValues, v[field.Name])
+		}
+	default:
+		return nil, fmt.Errorf("wrong tuple value format of %s", s.Name)
+	}
+	if len(fieldValues) != len(s.Fields) {
+		return nil, fmt.Errorf("Field count mismatch, expecting: %d, got: %d", len(s.Fields), len(fieldValues))
+	}
+
+	tuple := []interface{}{}
+	for i, field := range s.Fields {
+		if fieldValues[i] == nil {
+			return nil, fmt.Errorf("Field %s of %s not found", field.Name, s.Name)
+		}
+		fieldValue, err := parseJSONArg(field, fieldValues[i])
+		if err != nil {
+			return nil, err
+		}
+		tuple = append(tuple, fieldValue)
+	}
+	return tuple, nil
+}
+
 // EncodeFromString return []byte from an inputted types and values type of string slices
 func EncodeFromString(params []*Parameter, values []string) ([]byte, error) {
 	var interfaces []interface{}
@@ -270,7 +483,13 @@ func EncodeFromString(params []*Parameter, values []string) ([]byte, error) {
 		return []byte{0}, fmt.Errorf("Argument count mismatch, expecting: %d, got: %d", len(params), len(values))
 	}
 	for index, param := range params {
-		if param.IsArray {
+		if param.Type == Tuple {
+			arg, err := parseTupleArgFromString(param, values[index])
+			if err != nil {
+				return []byte{0}, err
+			}
+			interfaces = append(interfaces, arg)
+		} else if param.IsArray {
 			arg, err := parseArrayArgsFromString(param.Type, values[index])
 			if err != nil {
 				return []byte{0}, err
@@ -294,11 +513,16 @@ func EncodeFromString(params []*Parameter, values []string) ([]byte, error) {
 
 // EncodeHeaderToBytes encode a header file into byte array
 func EncodeHeaderToBytes(path string) ([]byte, error) {
-	var headerFile HeaderFile
 	headerFileContent, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	return EncodeHeaderJSONToBytes(headerFileContent)
+}
+
+// EncodeHeaderJSONToBytes encode content of a header file into byte array
+func EncodeHeaderJSONToBytes(headerFileContent []byte) ([]byte, error) {
+	var headerFile HeaderFile
 	if err := json.Unmarshal(headerFileContent, &headerFile); err != nil {
 		return nil, err
 	}
@@ -312,6 +536,21 @@ func EncodeHeaderToBytes(path string) ([]byte, error) {
 		Events:    []*Event{},
 	}
 
+	structs := make(map[string]*Struct)
+	for _, hStruct := range headerFile.Structs {
+		if _, ok := structs[hStruct.Name]; ok || hStruct.Name == Tuple.String() {
+			return nil, fmt.Errorf("duplicated struct %s", hStruct.Name)
+		}
+		if _, err := parsePrimitiveTypeFromString(hStruct.Name); err == nil {
+			return nil, fmt.Errorf("struct name %s is a primitive type", hStruct.Name)
+		}
+		s, err := parseStruct(hStruct.Name, hStruct.Fields, structs)
+		if err != nil {
+			return nil, err
+		}
+		structs[hStruct.Name] = s
+	}
+
 	for _, hFunction := range headerFile.Functions {
 		function := Function{
 			Name:       hFunction.Name,
@@ -319,22 +558,21 @@ func EncodeHeaderToBytes(path string) ([]byte, error) {
 			id:         crypto.GetMethodID(hFunction.Name),
 		}
 		for _, hParam := range hFunction.Parameters {
-			var parameter Parameter
-			var paramType PrimitiveType
-			var pType string
-			parameter.IsArray = hParam.IsArray()
-			if parameter.IsArray {
-				pType = hParam.Type[:len(hParam.Type)-2]
-			} else {
-				pType = hParam.Type
+			parameter, err := parseParameterFile(hParam, structs)
+			if err != nil {
+				return nil, err
 			}
-			paramType, err = parsePrimitiveTypeFromString(pType)
+			function.Parameters = append(function.Parameters, parameter)
+		}
+		if len(hFunction.Returns) > 1 {
+			return nil, fmt.Errorf("function %s returns more than one value", hFunction.Name)
+		}
+		for _, hReturn := range hFunction.Returns {
+			parameter, err := parseParameterFile(hReturn, structs)
 			if err != nil {
 				return nil, err
 			}
-			parameter.Type = paramType
-			parameter.Name = hParam.Name
-			function.Parameters = append(function.Parameters, &parameter)
+			function.Returns = append(function.Returns, parameter)
 		}
 		header.Functions = append(header.Functions, &function)
 	}
@@ -345,22 +583,11 @@ func EncodeHeaderToBytes(path string) ([]byte, error) {
 			Parameters: []*Parameter{},
 		}
 		for _, hParam := range hEvent.Parameters {
-			var parameter Parameter
-			var paramType PrimitiveType
-			var pType string
-			parameter.IsArray = hParam.IsArray()
-			if parameter.IsArray {
-				pType = hParam.Type[:len(hParam.Type)-2]
-			} else {
-				pType = hParam.Type
-			}
-			paramType, err = parsePrimitiveTypeFromString(pType)
+			parameter, err := parseParameterFile(hParam, structs)
 			if err != nil {
 				return nil, err
 			}
-			parameter.Type = paramType
-			parameter.Name = hParam.Name
-			event.Parameters = append(event.Parameters, &parameter)
+			event.Parameters = append(event.Parameters, parameter)
 		}
 		header.Events = append(header.Events, &event)
 	}
@@ -373,7 +600,7 @@ func EncodeHeaderToBytes(path string) ([]byte, error) {
 		return bytes.Compare(header.Events[i].id[:], header.Events[j].id[:]) == -1
 	})
 
-	return rlp.EncodeToBytes(header)
+	return encodeHeader(header.Version, header.Functions, header.Events)
 }
 
 // LoadHeaderFromFile load a header file into Header
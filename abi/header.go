@@ -9,9 +9,18 @@ import (
 	"sort"
 
 	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
 )
 
+// Versions of header encoding
+const (
+	// HeaderVersionV1 encodes parameters without size and supports types up to address
+	HeaderVersionV1 uint16 = 1
+	// HeaderVersionV2 encodes parameters with size and supports all types
+	HeaderVersionV2 uint16 = 2
+)
+
 var (
 	ErrDuplicatedFunctionsMethodID = errors.New("duplicated MethodID of functions")
 	ErrDuplicatedEventsMethodID    = errors.New("duplicated MethodID of events")
@@ -24,18 +33,131 @@ type Event struct {
 	id         crypto.MethodID
 }
 
+// UpgradeEvent is emitted by chain when contract of an account is upgraded
+var UpgradeEvent = &Event{
+	Name: "ContractUpgraded",
+	Parameters: []*Parameter{
+		{Name: "sender", Type: Address},
+		{Name: "previous", Type: Bytes, Size: common.HashLength},
+		{Name: "contract", Type: Bytes, Size: common.HashLength},
+	},
+	id: crypto.GetMethodID("ContractUpgraded"),
+}
+
+// ID returns method ID of event
+func (e *Event) ID() crypto.MethodID {
+	return e.id
+}
+
 // Parameter describes a param of method
 type Parameter struct {
 	Name    string        `json:"name"`
 	IsArray bool          `json:"-"`
 	Type    PrimitiveType `json:"type"`
+	// Size is length of fixed size array or bytes, 0 if size is given by value
+	Size uint `json:"size"`
+	// Tuple holds the struct of a tuple parameter
+	Tuple []*Struct `json:"-" rlp:"tail"`
+}
+
+// Struct describes fields of a struct, its name is empty for an anonymous tuple
+type Struct struct {
+	Name   string       `json:"name,omitempty"`
+	Fields []*Parameter `json:"fields"`
+}
+
+// Struct returns struct of a tuple parameter, nil for other types
+func (p *Parameter) Struct() *Struct {
+	if p.Type != Tuple || len(p.Tuple) == 0 {
+		return nil
+	}
+	return p.Tuple[0]
+}
+
+// IsPointer returns whether parameter is passed to contract by pointer
+func (p *Parameter) IsPointer() bool {
+	return p.IsArray || p.Type.IsPointer()
+}
+
+// GetMemorySize returns memory size of parameter, 0 if size is given by value or layout of tuple
+func (p *Parameter) GetMemorySize() int {
+	if p.Type == Tuple {
+		return 0
+	}
+	if p.Size > 0 {
+		return int(p.Size) * p.Type.GetMemorySize()
+	}
+	if p.IsArray || p.Type.IsDynamic() {
+		return 0
+	}
+	return p.Type.GetMemorySize()
+}
+
+// TypeName returns type of parameter as written in header file
+func (p *Parameter) TypeName() string {
+	typeName := p.Type.String()
+	if s := p.Struct(); s != nil && len(s.Name) > 0 {
+		typeName = s.Name
+	}
+	switch {
+	case p.IsArray && p.Size > 0:
+		return fmt.Sprintf("%s[%d]", typeName, p.Size)
+	case p.IsArray:
+		return typeName + "[]"
+	case p.Size > 0:
+		return fmt.Sprintf("%s%d", typeName, p.Size)
+	default:
+		return typeName
+	}
+}
+
+// collectStructs adds named structs of params and their fields to structs
+func collectStructs(params []*Parameter, structs map[string]*Struct) {
+	for _, param := range params {
+		if s := param.Struct(); s != nil {
+			if len(s.Name) > 0 {
+				structs[s.Name] = s
+			}
+			collectStructs(s.Fields, structs)
+		}
+	}
+}
+
+// validateParameters checks that every tuple parameter, nested ones included, holds one struct
+// with fields, and that other parameters hold none
+func validateParameters(params []*Parameter) error {
+	for _, param := range params {
+		if param.Type != Tuple {
+			if len(param.Tuple) > 0 {
+				return fmt.Errorf("parameter %s of type %s has struct", param.Name, param.TypeName())
+			}
+			continue
+		}
+		if len(param.Tuple) != 1 || param.Tuple[0] == nil || len(param.Tuple[0].Fields) == 0 {
+			return fmt.Errorf("tuple parameter %s has no struct fields", param.Name)
+		}
+		if err := validateParameters(param.Tuple[0].Fields); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Function describes a function in contract
 type Function struct {
 	Name       string       `json:"name"`
 	Parameters []*Parameter `json:"parameters"`
-	id         crypto.MethodID
+	// Returns is the return type, empty if function returns nothing
+	Returns []*Parameter `json:"returns,omitempty" rlp:"tail"`
+	id      crypto.MethodID
+}
+
+// ReturnType returns declared return type of function, nil if function declares none
+func (f *Function) ReturnType() *Parameter {
+	if len(f.Returns) == 0 {
+		return nil
+	}
+	return f.Returns[0]
 }
 
 // Header contains declaration for contract
@@ -43,6 +165,16 @@ type Header struct {
 	Version   uint16
 	Functions map[crypto.MethodID]*Function
 	Events    map[crypto.MethodID]*Event
+	// Structs are named structs used by functions and events
+	Structs map[string]*Struct
+}
+
+// GetStruct returns named struct
+func (h Header) GetStruct(name string) (*Struct, error) {
+	if s, ok := h.Structs[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("struct %s not found", name)
 }
 
 // GetFunctionByMethodID return Function by its id
@@ -69,17 +201,122 @@ func (h Header) GetFunction(funcName string) (*Function, error) {
 	return nil, fmt.Errorf("function %s not found", funcName)
 }
 
+// v1Parameter is encoding of Parameter in header version 1
+type v1Parameter struct {
+	Name    string
+	IsArray bool
+	Type    PrimitiveType
+}
+
+type v1Function struct {
+	Name       string
+	Parameters []*v1Parameter
+	Returns    []*v1Parameter `rlp:"tail"`
+}
+
+type v1Event struct {
+	Name       string
+	Parameters []*v1Parameter
+}
+
+type v1Header struct {
+	Version   uint16
+	Functions []*v1Function
+	Events    []*v1Event
+}
+
+func toV1Parameters(params []*Parameter) ([]*v1Parameter, error) {
+	v1Params := []*v1Parameter{}
+	for _, param := range params {
+		if param.Size > 0 || !param.Type.isV1() {
+			return nil, fmt.Errorf("type %s requires header version %d", param.TypeName(), HeaderVersionV2)
+		}
+		v1Params = append(v1Params, &v1Parameter{param.Name, param.IsArray, param.Type})
+	}
+	return v1Params, nil
+}
+
+func fromV1Parameters(v1Params []*v1Parameter) []*Parameter {
+	params := []*Parameter{}
+	for _, param := range v1Params {
+		params = append(params, &Parameter{Name: param.Name, IsArray: param.IsArray, Type: param.Type})
+	}
+	return params
+}
+
+// encodeHeader encodes functions and events in the format of header version
+func encodeHeader(version uint16, functions []*Function, events []*Event) ([]byte, error) {
+	if version >= HeaderVersionV2 {
+		return rlp.EncodeToBytes(struct {
+			Version   uint16
+			Functions []*Function
+			Events    []*Event
+		}{version, functions, events})
+	}
+
+	header := v1Header{Version: version, Functions: []*v1Function{}, Events: []*v1Event{}}
+	for _, function := range functions {
+		params, err := toV1Parameters(function.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		returns, err := toV1Parameters(function.Returns)
+		if err != nil {
+			return nil, err
+		}
+		header.Functions = append(header.Functions, &v1Function{function.Name, params, returns})
+	}
+	for _, event := range events {
+		params, err := toV1Parameters(event.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		header.Events = append(header.Events, &v1Event{event.Name, params})
+	}
+	return rlp.EncodeToBytes(header)
+}
+
 // DecodeHeader decode byte array of header into header
 func DecodeHeader(b []byte) (*Header, error) {
+	var versioned struct {
+		Version uint16
+		Rest    []rlp.RawValue `rlp:"tail"`
+	}
+	if err := rlp.DecodeBytes(b, &versioned); err != nil {
+		return nil, err
+	}
+
 	var header struct {
 		Version   uint16
 		Functions []*Function
 		Events    []*Event
 	}
-	if err := rlp.DecodeBytes(b, &header); err != nil {
-		return nil, err
+	if versioned.Version >= HeaderVersionV2 {
+		if err := rlp.DecodeBytes(b, &header); err != nil {
+			return nil, err
+		}
+	} else {
+		var v1 v1Header
+		if err := rlp.DecodeBytes(b, &v1); err != nil {
+			return nil, err
+		}
+		header.Version = v1.Version
+		for _, function := range v1.Functions {
+			header.Functions = append(header.Functions, &Function{
+				Name:       function.Name,
+				Parameters: fromV1Parameters(function.Parameters),
+				Returns:    fromV1Parameters(function.Returns),
+			})
+		}
+		for _, event := range v1.Events {
+			header.Events = append(header.Events, &Event{
+				Name:       event.Name,
+				Parameters: fromV1Parameters(event.Parameters),
+			})
+		}
 	}
 
+	structs := make(map[string]*Struct)
 	functions := make(map[crypto.MethodID]*Function)
 	for _, function := range header.Functions {
 		function.id = crypto.GetMethodID(function.Name)
@@ -87,6 +324,14 @@ func DecodeHeader(b []byte) (*Header, error) {
 			return nil, ErrDuplicatedFunctionsMethodID
 		}
 		functions[function.id] = function
+		if err := validateParameters(function.Parameters); err != nil {
+			return nil, err
+		}
+		if err := validateParameters(function.Returns); err != nil {
+			return nil, err
+		}
+		collectStructs(function.Parameters, structs)
+		collectStructs(function.Returns, structs)
 	}
 
 	events := make(map[crypto.MethodID]*Event)
@@ -96,9 +341,13 @@ func DecodeHeader(b []byte) (*Header, error) {
 			return nil, ErrDuplicatedEventsMethodID
 		}
 		events[event.id] = event
+		if err := validateParameters(event.Parameters); err != nil {
+			return nil, err
+		}
+		collectStructs(event.Parameters, structs)
 	}
 
-	return &Header{header.Version, functions, events}, nil
+	return &Header{header.Version, functions, events, structs}, nil
 }
 
 // Encode encode a header struct into byte array
@@ -139,17 +388,14 @@ func (h *Header) getFunctions() []*Function {
 	return functions
 }
 
-// EncodeRLP encodes a header to RLP format
+// EncodeRLP encodes a header to RLP format of its version
 func (h *Header) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, struct {
-		Version   uint16
-		Functions []*Function
-		Events    []*Event
-	}{
-		Version:   h.Version,
-		Functions: h.getFunctions(),
-		Events:    h.getEvents(),
-	})
+	encoded, err := encodeHeader(h.Version, h.getFunctions(), h.getEvents())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encoded)
+	return err
 }
 
 // MarshalJSON returns json string of header
@@ -167,13 +413,17 @@ func (h *Header) MarshalJSON() ([]byte, error) {
 
 // MarshalJSON returns json string of Parameter
 func (p *Parameter) MarshalJSON() ([]byte, error) {
+	var fields []*Parameter
+	if s := p.Struct(); s != nil {
+		fields = s.Fields
+	}
 	return json.Marshal(&struct {
-		Name    string `json:"name"`
-		IsArray bool   `json:"-"`
-		Type    string `json:"type"`
-		Size    uint   `json:"size,omitempty"`
+		Name   string       `json:"name"`
+		Type   string       `json:"type"`
+		Fields []*Parameter `json:"fields,omitempty"`
 	}{
-		Name: p.Name,
-		Type: p.Type.String(),
+		Name:   p.Name,
+		Type:   p.TypeName(),
+		Fields: fields,
 	})
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -160,3 +161,241 @@ func TestSortMethodIDs(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctionReturnType(t *testing.T) {
+	encoded, err := EncodeHeaderJSONToBytes([]byte(`{"version":1,"events":[],"functions":[
+		{"name":"values","parameters":[],"returns":[{"name":"values","type":"int32[]"}]},
+		{"name":"mint","parameters":[{"name":"amount","type":"uint64"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := DecodeHeader(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, _ := header.GetFunction("values")
+	want := &Parameter{Name: "values", IsArray: true, Type: Int32}
+	if diff := cmp.Diff(want, values.ReturnType()); diff != "" {
+		t.Errorf("ReturnType() mismatch (-want +got):\n%s", diff)
+	}
+	mint, _ := header.GetFunction("mint")
+	if mint.ReturnType() != nil {
+		t.Errorf("Expect no return type, got %v", mint.ReturnType())
+	}
+
+	_, err = EncodeHeaderJSONToBytes([]byte(`{"version":1,"events":[],"functions":[
+		{"name":"values","parameters":[],"returns":[{"type":"int32"},{"type":"int32"}]}]}`))
+	if err == nil || err.Error() != "function values returns more than one value" {
+		t.Errorf("Expect error for multiple return values, got %v", err)
+	}
+}
+
+func TestHeaderVersion(t *testing.T) {
+	headerJSON := `{"version":%d,"events":[{"name":"Sent","parameters":[{"name":"memo","type":"string"}]}],"functions":[
+		{"name":"send","parameters":[
+			{"name":"ok","type":"bool"},
+			{"name":"amount","type":"uint256"},
+			{"name":"hash","type":"bytes32"},
+			{"name":"data","type":"bytes"},
+			{"name":"owners","type":"address[2]"},
+			{"name":"values","type":"int128[]"}
+		],"returns":[{"name":"memo","type":"string"}]}]}`
+
+	if _, err := EncodeHeaderJSONToBytes([]byte(fmt.Sprintf(headerJSON, HeaderVersionV1))); err == nil || err.Error() != "type bool requires header version 2" {
+		t.Errorf("Expect error for new type in header version 1, got %v", err)
+	}
+
+	encoded, err := EncodeHeaderJSONToBytes([]byte(fmt.Sprintf(headerJSON, HeaderVersionV2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := DecodeHeader(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != HeaderVersionV2 {
+		t.Errorf("Expect version %d, got %d", HeaderVersionV2, header.Version)
+	}
+	send, err := header.GetFunction("send")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Parameter{
+		{Name: "ok", Type: Bool},
+		{Name: "amount", Type: Uint256},
+		{Name: "hash", Type: Bytes, Size: 32},
+		{Name: "data", Type: Bytes},
+		{Name: "owners", IsArray: true, Type: Address, Size: 2},
+		{Name: "values", IsArray: true, Type: Int128},
+	}
+	if diff := cmp.Diff(want, send.Parameters, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Parameters mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(&Parameter{Name: "memo", Type: String}, send.ReturnType(), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("ReturnType() mismatch (-want +got):\n%s", diff)
+	}
+
+	typeNames := []string{"bool", "uint256", "bytes32", "bytes", "address[2]", "int128[]"}
+	memorySizes := []int{1, 32, 32, 0, 2 * crypto.AddressLength, 0}
+	for i, param := range send.Parameters {
+		if param.TypeName() != typeNames[i] {
+			t.Errorf("Expect type name %s, got %s", typeNames[i], param.TypeName())
+		}
+		if param.GetMemorySize() != memorySizes[i] {
+			t.Errorf("Expect memory size of %s to be %d, got %d", typeNames[i], memorySizes[i], param.GetMemorySize())
+		}
+	}
+
+	reencoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, reencoded) {
+		t.Errorf("Expect header to be encoded as decoded")
+	}
+}
+
+func TestStructHeader(t *testing.T) {
+	encoded, err := EncodeHeaderJSONToBytes([]byte(`{"version":2,
+		"structs":[
+			{"name":"Asset","fields":[{"name":"id","type":"uint32"},{"name":"owner","type":"address"}]},
+			{"name":"Order","fields":[{"name":"asset","type":"Asset"},{"name":"prices","type":"uint64[]"}]}
+		],
+		"events":[{"name":"Placed","parameters":[{"name":"order","type":"Order"}]}],
+		"functions":[{"name":"place","parameters":[
+			{"name":"orders","type":"Order[]"},
+			{"name":"pair","type":"tuple","fields":[{"name":"base","type":"uint8"},{"name":"quote","type":"uint8"}]}
+		]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := DecodeHeader(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset := &Struct{Name: "Asset", Fields: []*Parameter{{Name: "id", Type: Uint32}, {Name: "owner", Type: Address}}}
+	order := &Struct{Name: "Order", Fields: []*Parameter{
+		{Name: "asset", Type: Tuple, Tuple: []*Struct{asset}},
+		{Name: "prices", IsArray: true, Type: Uint64},
+	}}
+	for name, want := range map[string]*Struct{"Asset": asset, "Order": order} {
+		s, err := header.GetStruct(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, s, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("GetStruct(%s) mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	place, _ := header.GetFunction("place")
+	if place.Parameters[0].TypeName() != "Order[]" || place.Parameters[1].TypeName() != "tuple" {
+		t.Errorf("Expect type names Order[] and tuple, got %s and %s", place.Parameters[0].TypeName(), place.Parameters[1].TypeName())
+	}
+
+	owner, _ := crypto.AddressFromString("LCHILMXMODD5DMDMPKVSD5MUODDQMBRU5GZVLGXEFBPG36HV4CLSYM7O")
+	byMap, err := Encode(place.Parameters, []interface{}{
+		[]interface{}{map[string]interface{}{
+			"asset":  map[string]interface{}{"id": uint32(1), "owner": owner},
+			"prices": []uint64{10, 20},
+		}},
+		map[string]interface{}{"base": uint8(1), "quote": uint8(2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bySlice, err := Encode(place.Parameters, []interface{}{
+		[]interface{}{[]interface{}{[]interface{}{uint32(1), owner}, []uint64{10, 20}}},
+		[]interface{}{uint8(1), uint8(2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byString, err := EncodeFromString(place.Parameters, []string{
+		`[{"asset":{"id":1,"owner":"LCHILMXMODD5DMDMPKVSD5MUODDQMBRU5GZVLGXEFBPG36HV4CLSYM7O"},"prices":[10,20]}]`,
+		`[1,2]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(byMap, bySlice) || !bytes.Equal(byMap, byString) {
+		t.Errorf("Expect tuple encodings to be equal")
+	}
+
+	args, err := DecodeToBytes(place.Parameters, byMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, err := DecodeTupleArray(place.Parameters[0], args[0])
+	if err != nil || len(orders) != 1 {
+		t.Fatalf("Expect 1 order, got %v, %v", orders, err)
+	}
+	fields, err := DecodeTuple(order, orders[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assetFields, err := DecodeTuple(asset, fields[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(assetFields[1], owner[:]) {
+		t.Errorf("Expect owner %v, got %v", owner[:], assetFields[1])
+	}
+
+	if _, err := Encode(place.Parameters[1:], []interface{}{map[string]interface{}{"base": uint8(1)}}); err == nil {
+		t.Errorf("Expect error for missing field")
+	}
+	if _, err := EncodeHeaderJSONToBytes([]byte(`{"version":1,"events":[],"functions":[
+		{"name":"f","parameters":[{"name":"t","type":"tuple","fields":[{"name":"a","type":"uint8"}]}]}]}`)); err == nil {
+		t.Errorf("Expect error for tuple in header version 1")
+	}
+}
+
+func TestDecodeHeaderInvalidTuple(t *testing.T) {
+	pair := &Struct{Fields: []*Parameter{{Name: "base", Type: Uint8}}}
+	tests := []struct {
+		name      string
+		functions []*Function
+		events    []*Event
+		want      string
+	}{{
+		name:      "tuple without struct",
+		functions: []*Function{{Name: "f", Parameters: []*Parameter{{Name: "t", Type: Tuple}}}},
+		want:      "tuple parameter t has no struct fields",
+	}, {
+		name:      "tuple without fields",
+		functions: []*Function{{Name: "f", Parameters: []*Parameter{{Name: "t", Type: Tuple, Tuple: []*Struct{{}}}}}},
+		want:      "tuple parameter t has no struct fields",
+	}, {
+		name: "nested tuple without struct",
+		functions: []*Function{{Name: "f", Parameters: []*Parameter{{Name: "t", Type: Tuple, Tuple: []*Struct{{
+			Fields: []*Parameter{{Name: "pair", Type: Tuple, Tuple: []*Struct{pair}}, {Name: "nested", IsArray: true, Type: Tuple}},
+		}}}}}},
+		want: "tuple parameter nested has no struct fields",
+	}, {
+		name:      "return tuple without struct",
+		functions: []*Function{{Name: "f", Parameters: []*Parameter{}, Returns: []*Parameter{{Name: "r", Type: Tuple}}}},
+		want:      "tuple parameter r has no struct fields",
+	}, {
+		name:   "event tuple without struct",
+		events: []*Event{{Name: "e", Parameters: []*Parameter{{Name: "t", Type: Tuple}}}},
+		want:   "tuple parameter t has no struct fields",
+	}, {
+		name:      "struct of other type",
+		functions: []*Function{{Name: "f", Parameters: []*Parameter{{Name: "a", Type: Uint8, Tuple: []*Struct{pair}}}}},
+		want:      "parameter a of type uint8 has struct",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := encodeHeader(HeaderVersionV2, tt.functions, tt.events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := DecodeHeader(encoded); err == nil || err.Error() != tt.want {
+				t.Errorf("Expect error %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"math/big"
 
 	"github.com/QuoineFinancial/liquid-chain/crypto"
 )
@@ -24,18 +25,46 @@ const (
 	Float32 PrimitiveType = 0x8
 	Float64 PrimitiveType = 0x9
 	Address PrimitiveType = 0xa
+	Bool    PrimitiveType = 0xb
+	Uint128 PrimitiveType = 0xc
+	Uint256 PrimitiveType = 0xd
+	Int128  PrimitiveType = 0xe
+	Int256  PrimitiveType = 0xf
+	Bytes   PrimitiveType = 0x10
+	String  PrimitiveType = 0x11
+	Tuple   PrimitiveType = 0x12
 )
 
 // IsPointer return whether p is pointer or not
 func (t PrimitiveType) IsPointer() bool {
 	switch t {
-	case Address:
+	case Address, Uint128, Uint256, Int128, Int256, Bytes, String, Tuple:
 		return true
 	default:
 		return false
 	}
 }
 
+// IsDynamic returns whether memory size of type is given by its value, as of bytes and string
+func (t PrimitiveType) IsDynamic() bool {
+	return t == Bytes || t == String
+}
+
+// IsBigInt returns whether type is a 128 or 256 bits integer
+func (t PrimitiveType) IsBigInt() bool {
+	switch t {
+	case Uint128, Uint256, Int128, Int256:
+		return true
+	default:
+		return false
+	}
+}
+
+// isV1 returns whether type is supported by header version 1
+func (t PrimitiveType) isV1() bool {
+	return t <= Address
+}
+
 // IsAddress check if this type is an Address
 func (t PrimitiveType) IsAddress() bool {
 	return t == Address
@@ -54,15 +83,23 @@ func (t PrimitiveType) String() string {
 		Float32: "float32",
 		Float64: "float64",
 		Address: "address",
+		Bool:    "bool",
+		Uint128: "uint128",
+		Uint256: "uint256",
+		Int128:  "int128",
+		Int256:  "int256",
+		Bytes:   "bytes",
+		String:  "string",
+		Tuple:   "tuple",
 	}[t]
 }
 
-// GetMemorySize returns memory size for a primitive type
+// GetMemorySize returns memory size for a primitive type, which is size of a byte for bytes and string
 func (t PrimitiveType) GetMemorySize() int {
 	switch t {
 	case Address:
 		return crypto.AddressLength
-	case Uint8, Int8:
+	case Uint8, Int8, Bool, Bytes, String:
 		return 1
 	case Uint16, Int16:
 		return 2
@@ -70,6 +107,10 @@ func (t PrimitiveType) GetMemorySize() int {
 		return 4
 	case Uint64, Int64, Float64:
 		return 8
+	case Uint128, Int128:
+		return 16
+	case Uint256, Int256:
+		return 32
 	default:
 		panic("primitive type not found")
 	}
@@ -77,12 +118,24 @@ func (t PrimitiveType) GetMemorySize() int {
 
 // NewArgument returns a vm-compatible byte array from an interface
 func (t PrimitiveType) NewArgument(value interface{}) ([]byte, error) {
+	switch t {
+	case Uint128, Uint256, Int128, Int256:
+		return t.newBigIntArgument(value.(*big.Int))
+	case Bytes:
+		return append([]byte{}, value.([]byte)...), nil
+	case String:
+		return []byte(value.(string)), nil
+	}
 	memorySize := t.GetMemorySize()
 	buf := make([]byte, memorySize)
 	switch t {
 	case Address:
 		address := value.(crypto.Address)
 		copy(buf, address[:])
+	case Bool:
+		if value.(bool) {
+			buf[0] = 1
+		}
 	case Uint8:
 		buf[0] = byte(value.(uint8))
 	case Uint16:
@@ -109,6 +162,45 @@ func (t PrimitiveType) NewArgument(value interface{}) ([]byte, error) {
 	return buf, nil
 }
 
+// newBigIntArgument returns little endian two's complement of value
+func (t PrimitiveType) newBigIntArgument(value *big.Int) ([]byte, error) {
+	memorySize := t.GetMemorySize()
+	bits := uint(memorySize * 8)
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), bits)
+	if t == Int128 || t == Int256 {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if value.Cmp(min) < 0 || value.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("value %s out of range of %s", value, t)
+	}
+
+	unsigned := new(big.Int).Set(value)
+	if unsigned.Sign() < 0 {
+		unsigned.Add(unsigned, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+	buf := unsigned.FillBytes(make([]byte, memorySize))
+	reverseBytes(buf)
+	return buf, nil
+}
+
+// DecodeBigInt returns integer from little endian two's complement bytes of 128 or 256 bits integer type
+func (t PrimitiveType) DecodeBigInt(value []byte) *big.Int {
+	buf := append([]byte{}, value...)
+	reverseBytes(buf)
+	result := new(big.Int).SetBytes(buf)
+	if (t == Int128 || t == Int256) && len(buf) > 0 && buf[0]&0x80 != 0 {
+		result.Sub(result, new(big.Int).Lsh(big.NewInt(1), uint(len(buf)*8)))
+	}
+	return result
+}
+
+func reverseBytes(buf []byte) {
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+}
+
 // NewArrayArgument returns a vm-compatible byte array from an interface of array
 func (t PrimitiveType) NewArrayArgument(value interface{}) ([]byte, error) {
 	var parsedArgs []byte
@@ -245,6 +337,30 @@ func (t PrimitiveType) NewArrayArgument(value interface{}) ([]byte, error) {
 			}
 			parsedArgs = append(parsedArgs, arg...)
 		}
+	case Bool:
+		parsed, ok := value.([]bool)
+		if !ok {
+			return nil, fmt.Errorf("unable to convert array element into %s", t.String())
+		}
+		for _, p := range parsed {
+			arg, err := t.NewArgument(p)
+			if err != nil {
+				return nil, err
+			}
+			parsedArgs = append(parsedArgs, arg...)
+		}
+	case Uint128, Uint256, Int128, Int256:
+		parsed, ok := value.([]*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("unable to convert array element into %s", t.String())
+		}
+		for _, p := range parsed {
+			arg, err := t.NewArgument(p)
+			if err != nil {
+				return nil, err
+			}
+			parsedArgs = append(parsedArgs, arg...)
+		}
 	default:
 		return nil, fmt.Errorf("not supported type: %s", t)
 	}
@@ -1,7 +1,9 @@
 package abi
 
 import (
+	"bytes"
 	"errors"
+	"math/big"
 	"testing"
 )
 
@@ -98,3 +100,38 @@ func TestNewArrayArgument(t *testing.T) {
 		}
 	}
 }
+
+func TestBigIntArgument(t *testing.T) {
+	tests := []struct {
+		primitiveType PrimitiveType
+		value         string
+		encoded       []byte
+		err           error
+	}{
+		{Uint128, "1", append([]byte{1}, make([]byte, 15)...), nil},
+		{Int128, "-1", bytes.Repeat([]byte{0xff}, 16), nil},
+		{Int256, "-256", append([]byte{0}, bytes.Repeat([]byte{0xff}, 31)...), nil},
+		{Uint256, "-1", nil, errors.New("value -1 out of range of uint256")},
+		{Int128, "170141183460469231731687303715884105728", nil, errors.New("value 170141183460469231731687303715884105728 out of range of int128")},
+	}
+
+	for _, test := range tests {
+		value, _ := new(big.Int).SetString(test.value, 10)
+		encoded, err := test.primitiveType.NewArgument(value)
+		if test.err != nil {
+			if err == nil || err.Error() != test.err.Error() {
+				t.Errorf("Expect error %v, got %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded, test.encoded) {
+			t.Errorf("Encoding of %s %s is incorrect, expecting: %#v, got: %#v", test.primitiveType, test.value, test.encoded, encoded)
+		}
+		if decoded := test.primitiveType.DecodeBigInt(encoded); decoded.Cmp(value) != 0 {
+			t.Errorf("Expect decoded value %s, got %s", value, decoded)
+		}
+	}
+}
@@ -29,10 +29,10 @@ type API struct {
 }
 
 // NewAPI return an new instance of API
-func NewAPI(url, tmURL, rootDir string, metaDB, stateDB, chainDB db.Database) *API {
+func NewAPI(url, tmURL string, metaDB, stateDB, chainDB db.Database) *API {
 	api := &API{
 		url:   url,
-		tmAPI: resource.NewTendermintAPI(rootDir, tmURL),
+		tmAPI: resource.NewTendermintAPI(tmURL),
 		meta:  storage.NewMetaStorage(metaDB),
 		state: storage.NewStateStorage(stateDB),
 		chain: storage.NewChainStorage(chainDB),
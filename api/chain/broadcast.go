@@ -31,7 +31,7 @@ func (service *Service) Broadcast(r *http.Request, params *BroadcastParams, resu
 		return err
 	}
 
-	tmResult, err := service.tmAPI.BroadcastTxSync(bytes)
+	tmResult, err := service.tmAPI.BroadcastTxSync(r.Context(), bytes)
 	if err != nil {
 		return err
 	}
@@ -55,7 +55,7 @@ func (service *Service) BroadcastAsync(r *http.Request, params *BroadcastParams,
 		return err
 	}
 
-	tmResult, err := service.tmAPI.BroadcastTxAsync(bytes)
+	tmResult, err := service.tmAPI.BroadcastTxAsync(r.Context(), bytes)
 	if err != nil {
 		return err
 	}
@@ -79,7 +79,7 @@ func (service *Service) BroadcastCommit(r *http.Request, params *BroadcastParams
 		return err
 	}
 
-	tmResult, err := service.tmAPI.BroadcastTxCommit(bytes)
+	tmResult, err := service.tmAPI.BroadcastTxCommit(r.Context(), bytes)
 	if err != nil {
 		return err
 	}
@@ -2,7 +2,6 @@ package chain
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/QuoineFinancial/liquid-chain/abi"
@@ -17,11 +16,14 @@ type CallParams struct {
 	Address string   `json:"address"`
 	Method  string   `json:"method"`
 	Args    []string `json:"args"`
+	// Calls are read-only unless writable, read-only calls fail if contract writes storage, emits events or deploys contracts.
+	// Changes of writable calls are dropped after the call
+	Writable bool `json:"writable"`
 }
 
 // CallResult is result of Call
 type CallResult struct {
-	Result string             `json:"result"`
+	Result interface{}        `json:"result"`
 	Code   crypto.ReceiptCode `json:"code"`
 	Events []*call            `json:"events"`
 }
@@ -31,7 +33,9 @@ func (service *Service) Call(r *http.Request, params *CallParams, result *CallRe
 	if params.Height == nil {
 		service.syncLatestState()
 	} else {
-		service.syncStateAt(*params.Height)
+		if err := service.syncStateAt(*params.Height); err != nil {
+			return err
+		}
 	}
 
 	address, err := crypto.AddressFromString(params.Address)
@@ -51,7 +55,13 @@ func (service *Service) Call(r *http.Request, params *CallParams, result *CallRe
 	senderAddress := crypto.EmptyAddress
 	var app gas.App
 	station := gas.NewFreeStation(app)
-	execEngine := engine.NewEngine(service.state, contractAccount, senderAddress, station.GetPolicy(), 0)
+	execEngine := engine.NewStaticEngine(service.state, contractAccount, senderAddress, station.GetPolicy(), 0)
+	if params.Writable {
+		execEngine = engine.NewEngine(service.state, contractAccount, senderAddress, station.GetPolicy(), 0)
+	}
+	// Changes of call are never committed, drop them so they do not leak into later requests
+	snapshot := service.state.Snapshot()
+	defer service.state.RevertToSnapshot(snapshot)
 
 	contract, err := contractAccount.GetContract()
 	if err != nil {
@@ -73,7 +83,9 @@ func (service *Service) Call(r *http.Request, params *CallParams, result *CallRe
 		return err
 	}
 
-	result.Result = fmt.Sprintf("%x", igniteResult)
+	if result.Result, err = parseResult(function, igniteResult, execEngine.GetReturnData()); err != nil {
+		return err
+	}
 	result.Code = crypto.ReceiptCodeOK
 
 	parsedEvents := []*call{}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
 )
 
@@ -15,6 +16,8 @@ type GetContractParams struct {
 // GetContractResult is result of GetAccount
 type GetContractResult struct {
 	Contract *abi.Contract `json:"contract"`
+	// Upgrades are hashes of transactions which upgraded contract, oldest first
+	Upgrades []common.Hash `json:"upgrades"`
 }
 
 // GetContract gets contract from account state of given address
@@ -33,5 +36,6 @@ func (service *Service) GetContract(r *http.Request, params *GetContractParams,
 		return err
 	}
 	result.Contract = contract
+	result.Upgrades = service.meta.ContractUpgrades(address)
 	return nil
 }
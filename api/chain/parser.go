@@ -3,6 +3,7 @@ package chain
 import (
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
@@ -39,16 +40,88 @@ func parseParam(param *abi.Parameter, value []byte) (string, error) {
 	case abi.Int32:
 		return fmt.Sprintf("%d", int32(binary.LittleEndian.Uint32(value))), nil
 	case abi.Int64:
-		return fmt.Sprintf("%d", int64(binary.LittleEndian.Uint32(value))), nil
+		return fmt.Sprintf("%d", int64(binary.LittleEndian.Uint64(value))), nil
 	case abi.Float32:
 		return fmt.Sprintf("%f", math.Float32frombits(binary.LittleEndian.Uint32(value))), nil
 	case abi.Float64:
 		return fmt.Sprintf("%f", math.Float64frombits(binary.LittleEndian.Uint64(value))), nil
+	case abi.Bool:
+		return fmt.Sprintf("%t", value[0] != 0), nil
+	case abi.Uint128, abi.Uint256, abi.Int128, abi.Int256:
+		return param.Type.DecodeBigInt(value).String(), nil
+	case abi.Bytes:
+		return hex.EncodeToString(value), nil
+	case abi.String:
+		return string(value), nil
 	}
 
 	return "", errors.New("unsupported type")
 }
 
+// parseValue decodes tuples into objects of field name to value, others into string
+func parseValue(param *abi.Parameter, value []byte) (interface{}, error) {
+	if param.Type != abi.Tuple {
+		return parseParam(param, value)
+	}
+	if !param.IsArray {
+		return parseTuple(param.Struct(), value)
+	}
+	tuples, err := abi.DecodeTupleArray(param, value)
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for _, tuple := range tuples {
+		parsed, err := parseTuple(param.Struct(), tuple)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, parsed)
+	}
+	return values, nil
+}
+
+func parseTuple(s *abi.Struct, value []byte) (map[string]interface{}, error) {
+	fields, err := abi.DecodeTuple(s, value)
+	if err != nil {
+		return nil, err
+	}
+	tuple := make(map[string]interface{})
+	for i, field := range s.Fields {
+		parsed, err := parseValue(field, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		name := field.Name
+		if len(name) == 0 {
+			name = fmt.Sprint(i)
+		}
+		tuple[name] = parsed
+	}
+	return tuple, nil
+}
+
+// parseResult decodes result of function by its return type.
+// Arrays and pointer types are read from return data, others from the result itself
+func parseResult(function *abi.Function, result uint64, returnData []byte) (interface{}, error) {
+	var returnType *abi.Parameter
+	if function != nil {
+		returnType = function.ReturnType()
+	}
+	if returnType == nil {
+		return fmt.Sprintf("%x", result), nil
+	}
+	if returnType.IsPointer() {
+		if len(returnData) == 0 {
+			return fmt.Sprintf("%x", result), nil
+		}
+		return parseValue(returnType, returnData)
+	}
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, result)
+	return parseParam(returnType, value)
+}
+
 func parseFunction(methodID crypto.MethodID, args []byte, contract *abi.Contract) (*call, error) {
 	if contract == nil {
 		return nil, nil
@@ -65,16 +138,12 @@ func parseFunction(methodID crypto.MethodID, args []byte, contract *abi.Contract
 	}
 	for i, arg := range parsedArgs {
 		param := function.Parameters[i]
-		value, err := parseParam(param, arg)
+		value, err := parseValue(param, arg)
 		if err != nil {
 			return nil, err
 		}
-		typeName := param.Type.String()
-		if param.IsArray {
-			typeName = typeName + "[]"
-		}
 		call.Args = append(call.Args, argument{
-			Type:  typeName,
+			Type:  param.TypeName(),
 			Name:  param.Name,
 			Value: value,
 		})
@@ -93,7 +162,12 @@ func (service *Service) parseEvent(methodID crypto.MethodID, args []byte, addres
 		return nil, err
 	}
 
-	event := contract.Header.Events[methodID]
+	event, ok := contract.Header.Events[methodID]
+	if !ok && methodID == abi.UpgradeEvent.ID() {
+		event = abi.UpgradeEvent
+	} else if !ok {
+		return nil, fmt.Errorf("event with methodID %v not found", methodID)
+	}
 
 	parsedArgs, err := abi.DecodeToBytes(event.Parameters, args)
 	if err != nil {
@@ -106,16 +180,12 @@ func (service *Service) parseEvent(methodID crypto.MethodID, args []byte, addres
 	}
 	for i, arg := range parsedArgs {
 		param := event.Parameters[i]
-		value, err := parseParam(param, arg)
+		value, err := parseValue(param, arg)
 		if err != nil {
 			return nil, err
 		}
-		typeName := param.Type.String()
-		if param.IsArray {
-			typeName = typeName + "[]"
-		}
 		call.Args = append(call.Args, argument{
-			Type:  typeName,
+			Type:  param.TypeName(),
 			Name:  param.Name,
 			Value: value,
 		})
@@ -136,28 +206,17 @@ func (service *Service) parseTransaction(tx *crypto.Transaction, blockHeight uin
 		Signature:   tx.Signature,
 	}
 
-	var contract *abi.Contract
-	if tx.Receiver != crypto.EmptyAddress {
+	if tx.Receiver != crypto.EmptyAddress && len(tx.Payload.Contract) > 0 {
+		parsedTx.Type = transactionTypeUpgrade
+	} else if tx.Receiver != crypto.EmptyAddress {
 		parsedTx.Type = transactionTypeInvoke
-		account, err := service.state.GetAccount(tx.Receiver)
-		if err != nil {
-			return nil, err
-		}
-		c, err := account.GetContract()
-		if err != nil {
-			return nil, err
-		}
-		contract = c
 	} else {
 		parsedTx.Type = transactionTypeDeploy
-		parsedTx.Receiver = crypto.NewDeploymentAddress(
-			crypto.AddressFromPubKey(tx.Sender.PublicKey),
-			tx.Sender.Nonce,
-		)
-		c, err := abi.DecodeContract(tx.Payload.Contract)
-		if err == nil {
-			contract = c
-		}
+		parsedTx.Receiver = tx.DeploymentAddress()
+	}
+	contract, err := service.getTransactionContract(tx)
+	if err != nil {
+		return nil, err
 	}
 
 	if tx.Payload.ID != (crypto.MethodID{}) {
@@ -173,11 +232,43 @@ func (service *Service) parseTransaction(tx *crypto.Transaction, blockHeight uin
 	return &parsedTx, nil
 }
 
-func (service *Service) parseReceipt(r *crypto.Receipt) (*receipt, error) {
+// getTransactionContract returns contract invoked, deployed or upgraded to by tx, nil if it cannot be decoded
+func (service *Service) getTransactionContract(tx *crypto.Transaction) (*abi.Contract, error) {
+	if tx.Receiver == crypto.EmptyAddress || len(tx.Payload.Contract) > 0 {
+		contract, err := abi.DecodeContract(tx.Payload.Contract)
+		if err != nil {
+			return nil, nil
+		}
+		return contract, nil
+	}
+	account, err := service.state.GetAccount(tx.Receiver)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, nil
+	}
+	return account.GetContract()
+}
+
+func (service *Service) parseReceipt(r *crypto.Receipt, tx *crypto.Transaction) (*receipt, error) {
+	contract, err := service.getTransactionContract(tx)
+	if err != nil {
+		return nil, err
+	}
+	var function *abi.Function
+	if contract != nil {
+		function = contract.Header.Functions[tx.Payload.ID]
+	}
+	result, err := parseResult(function, r.Result, r.ReturnData())
+	if err != nil {
+		return nil, err
+	}
+
 	parsedReceipt := receipt{
 		Index:       r.Index,
 		Transaction: r.Transaction,
-		Result:      fmt.Sprintf("%x", r.Result),
+		Result:      result,
 		Code:        r.Code,
 		GasUsed:     r.GasUsed,
 		Events:      make([]call, 0),
@@ -206,7 +297,9 @@ func (service *Service) parseBlock(rawBlock *crypto.Block) (*block, error) {
 		Receipts:        []receipt{},
 	}
 
+	txs := make(map[common.Hash]*crypto.Transaction)
 	for _, tx := range rawBlock.Transactions() {
+		txs[tx.Hash()] = tx
 		parsedTx, err := service.parseTransaction(tx, rawBlock.Height)
 		if err != nil {
 			return nil, err
@@ -216,11 +309,11 @@ func (service *Service) parseBlock(rawBlock *crypto.Block) (*block, error) {
 
 	txHashToReceipt := make(map[common.Hash]*receipt)
 	for _, receipt := range rawBlock.Receipts() {
-		parsedReceipt, err := service.parseReceipt(receipt)
-		txHashToReceipt[parsedReceipt.Transaction] = parsedReceipt
+		parsedReceipt, err := service.parseReceipt(receipt, txs[receipt.Transaction])
 		if err != nil {
 			return nil, err
 		}
+		txHashToReceipt[parsedReceipt.Transaction] = parsedReceipt
 		parsedBlock.Receipts = append(parsedBlock.Receipts, *parsedReceipt)
 	}
 
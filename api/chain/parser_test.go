@@ -0,0 +1,75 @@
+package chain
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseResult(t *testing.T) {
+	address, _ := crypto.AddressFromString("LA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCTXXT")
+	function := func(returnType *abi.Parameter) *abi.Function {
+		return &abi.Function{Name: "f", Returns: []*abi.Parameter{returnType}}
+	}
+	tests := []struct {
+		name       string
+		function   *abi.Function
+		result     uint64
+		returnData []byte
+		want       string
+	}{
+		{"no function", nil, 1000, nil, "3e8"},
+		{"no return type", &abi.Function{Name: "f"}, 1000, nil, "3e8"},
+		{"int32", function(&abi.Parameter{Type: abi.Int32}), uint64(math.MaxUint32), nil, "-1"},
+		{"int64", function(&abi.Parameter{Type: abi.Int64}), math.MaxUint64, nil, "-1"},
+		{"uint64", function(&abi.Parameter{Type: abi.Uint64}), 1000, nil, "1000"},
+		{"float64", function(&abi.Parameter{Type: abi.Float64}), math.Float64bits(2.5), nil, "2.500000"},
+		{"address", function(&abi.Parameter{Type: abi.Address}), 0, address[:], address.String()},
+		{"array", function(&abi.Parameter{Type: abi.Uint8, IsArray: true}), 3, []byte("abc"), "YWJj"},
+		{"array without return data", function(&abi.Parameter{Type: abi.Uint8, IsArray: true}), 3, nil, "3"},
+		{"bool", function(&abi.Parameter{Type: abi.Bool}), 1, nil, "true"},
+		{"int128", function(&abi.Parameter{Type: abi.Int128}), 0, bytes.Repeat([]byte{0xff}, 16), "-1"},
+		{"bytes32", function(&abi.Parameter{Type: abi.Bytes, Size: 32}), 0, make([]byte, 32), strings.Repeat("00", 32)},
+		{"string", function(&abi.Parameter{Type: abi.String}), 0, []byte("liquid"), "liquid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseResult(tt.function, tt.result, tt.returnData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parseResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTupleValue(t *testing.T) {
+	pair := &abi.Struct{Name: "Pair", Fields: []*abi.Parameter{{Name: "base", Type: abi.Uint8}, {Name: "quote", Type: abi.String}}}
+	param := &abi.Parameter{Name: "pairs", IsArray: true, Type: abi.Tuple, Tuple: []*abi.Struct{pair}}
+	encoded, err := abi.Encode([]*abi.Parameter{param}, []interface{}{
+		[]interface{}{[]interface{}{uint8(1), "usd"}, []interface{}{uint8(2), "jpy"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, _ := abi.DecodeToBytes([]*abi.Parameter{param}, encoded)
+
+	got, err := parseValue(param, args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		map[string]interface{}{"base": "1", "quote": "usd"},
+		map[string]interface{}{"base": "2", "quote": "jpy"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseValue() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -23,14 +23,17 @@ func NewService(
 	return &Service{tmAPI, meta, state, block}
 }
 
-func (service *Service) syncStateAt(blockHeight uint64) {
+func (service *Service) syncStateAt(blockHeight uint64) error {
+	if err := service.meta.CheckStateHeight(blockHeight); err != nil {
+		return err
+	}
 	latestBlockHash := service.meta.BlockHeightToBlockHash(blockHeight)
 	latestBlock := service.block.MustGetBlock(latestBlockHash)
-	if err := service.state.LoadState(latestBlock); err != nil {
-		panic(err)
-	}
+	return service.state.LoadState(latestBlock)
 }
 
 func (service *Service) syncLatestState() {
-	service.syncStateAt(service.meta.LatestBlockHeight())
+	if err := service.syncStateAt(service.meta.LatestBlockHeight()); err != nil {
+		panic(err)
+	}
 }
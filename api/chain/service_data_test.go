@@ -3,17 +3,18 @@ package chain
 import (
 	"crypto/ed25519"
 	"fmt"
-	"math/rand"
+	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
 	"time"
 
 	"github.com/QuoineFinancial/liquid-chain/consensus"
 	"github.com/QuoineFinancial/liquid-chain/constant"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/db"
 	"github.com/QuoineFinancial/liquid-chain/util"
 	"github.com/tendermint/tendermint/abci/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 )
 
 type testResource struct {
@@ -109,7 +110,7 @@ func (resource testResource) seed() {
 	appHash := []byte{}
 	for _, round := range rounds {
 		app.BeginBlock(types.RequestBeginBlock{
-			Header: types.Header{
+			Header: tmproto.Header{
 				Height:  round.height,
 				Time:    round.time,
 				AppHash: appHash,
@@ -130,14 +131,12 @@ func (resource testResource) seed() {
 }
 
 func newTestResource() *testResource {
-	rand.Seed(time.Now().UTC().UnixNano())
-	dbDir := "./tmp" + strconv.Itoa(rand.Intn(10000)) + "/"
-
-	if err := os.MkdirAll(dbDir, os.ModePerm); err != nil {
+	dbDir, err := ioutil.TempDir("", "liquid-chain-api")
+	if err != nil {
 		panic(err)
 	}
 
-	app := consensus.NewApp(filepath.Join(dbDir, "liquid"), "")
+	app := consensus.NewApp(filepath.Join(dbDir, "liquid"), db.DefaultBackend)
 	if err := app.State.LoadState(&crypto.GenesisBlock); err != nil {
 		panic(err)
 	}
@@ -170,7 +169,7 @@ func (resource testResource) getDeployEventStringTx(senderIndex byte, nonce int)
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -189,7 +188,7 @@ func (resource testResource) getDeployTx(senderIndex byte, nonce int) *crypto.Tr
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -209,7 +208,7 @@ func (resource testResource) getInvokeTx(senderIndex byte, nonce int) *crypto.Tr
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -263,7 +262,7 @@ func (resource testResource) getInvalidNonceTx(senderIndex byte, nonce int) *cry
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -283,7 +282,7 @@ func (resource testResource) getInvalidGasPriceTx(senderIndex byte, nonce int) *
 		GasLimit: 0,
 		GasPrice: 0,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -303,7 +302,7 @@ func (resource testResource) getInvokeNilContractTx(senderIndex byte, nonce int)
 		GasLimit: 0,
 		GasPrice: 0,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -323,7 +322,7 @@ func (resource testResource) getInvokeNonContractTx(senderIndex byte, nonce int)
 		GasLimit: 0,
 		GasPrice: 0,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -343,7 +342,7 @@ func (resource testResource) getInvalidSerializedTx(senderIndex byte, nonce int)
 		GasLimit: 0,
 		GasPrice: 0,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, "")
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -314,9 +314,10 @@ func TestCall(t *testing.T) {
 	}, {
 		name: "ignite with events",
 		params: CallParams{
-			Address: "LA3K6XGDQXAZN6J22J5VCEFIU25PE4BEZRZE5K76WDGUIRV3HLKJALPV",
-			Method:  "say",
-			Args:    []string{"1"},
+			Address:  "LA3K6XGDQXAZN6J22J5VCEFIU25PE4BEZRZE5K76WDGUIRV3HLKJALPV",
+			Method:   "say",
+			Args:     []string{"1"},
+			Writable: true,
 		},
 		result: CallResult{
 			Result: "1",
@@ -332,6 +333,27 @@ func TestCall(t *testing.T) {
 			}},
 		},
 		wantErr: false,
+	}, {
+		name: "read-only call",
+		params: CallParams{
+			Address: "LBAPQ4LVHFYZQXRSS3CCN6VUZ2EEC6IN5S2RGQLHS3RNNOIBNP4B6XNH",
+			Method:  "get_balance",
+			Args:    []string{"LA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCTXXT"},
+		},
+		result: CallResult{
+			Result: "3e8",
+			Code:   crypto.ReceiptCodeOK,
+			Events: []*call{},
+		},
+		wantErr: false,
+	}, {
+		name: "read-only call with events",
+		params: CallParams{
+			Address: "LA3K6XGDQXAZN6J22J5VCEFIU25PE4BEZRZE5K76WDGUIRV3HLKJALPV",
+			Method:  "say",
+			Args:    []string{"1"},
+		},
+		wantErr: true,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
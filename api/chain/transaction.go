@@ -78,7 +78,7 @@ func (service *Service) GetTransaction(r *http.Request, params *GetTransactionPa
 	if err != nil {
 		return err
 	}
-	parsedReceipt, err := service.parseReceipt(receipt)
+	parsedReceipt, err := service.parseReceipt(receipt, tx)
 	if err != nil {
 		return err
 	}
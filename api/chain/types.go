@@ -6,9 +6,9 @@ import (
 )
 
 type argument struct {
-	Type  string `json:"type"`
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	Type  string      `json:"type"`
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
 }
 
 type call struct {
@@ -20,7 +20,7 @@ type call struct {
 type receipt struct {
 	Index       uint32             `json:"index"`
 	Transaction common.Hash        `json:"transaction"`
-	Result      string             `json:"result"`
+	Result      interface{}        `json:"result"`
 	GasUsed     uint32             `json:"gasUsed"`
 	Code        crypto.ReceiptCode `json:"code"`
 	Events      []call             `json:"events"`
@@ -30,8 +30,9 @@ type receipt struct {
 type transactionType string
 
 const (
-	transactionTypeDeploy transactionType = "deploy"
-	transactionTypeInvoke transactionType = "invoke"
+	transactionTypeDeploy  transactionType = "deploy"
+	transactionTypeInvoke  transactionType = "invoke"
+	transactionTypeUpgrade transactionType = "upgrade"
 )
 
 type transaction struct {
@@ -14,7 +14,7 @@ func main() {
 		rootDir = rootDirEnv
 	}
 
-	// TODO: Get gasContractAddress from genesis file
+	// Chains initialized without gas contract in genesis app_state still read it from environment
 	gasContractAddress := os.Getenv("GAS_CONTRACT_ADDRESS")
 	liquidNode := node.New(rootDir, gasContractAddress)
 	liquidNode.Execute()
@@ -0,0 +1,89 @@
+package node
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/QuoineFinancial/liquid-chain/consensus"
+	"github.com/QuoineFinancial/liquid-chain/db"
+	"github.com/spf13/cobra"
+)
+
+const migrateBatchSize = 10000
+
+// MigrateDB copies app databases in dbDir from source backend to target backend.
+// The source databases are kept in <dbDir>.<source>.bak
+func MigrateDB(dbDir string, source db.Backend, target db.Backend) error {
+	if source == target {
+		return fmt.Errorf("Database is already %s", target)
+	}
+	if _, err := os.Stat(dbDir); err != nil {
+		return err
+	}
+
+	targetDir := dbDir + ".migrating"
+	if err := os.RemoveAll(targetDir); err != nil {
+		return err
+	}
+	sourceDBs, err := openAppDatabases(dbDir, source)
+	if err != nil {
+		return err
+	}
+	targetDBs, err := openAppDatabases(targetDir, target)
+	if err != nil {
+		closeDatabases(sourceDBs)
+		return err
+	}
+	for i := range sourceDBs {
+		if _, err := db.Copy(targetDBs[i], sourceDBs[i], migrateBatchSize); err != nil {
+			closeDatabases(sourceDBs)
+			closeDatabases(targetDBs)
+			return err
+		}
+	}
+	closeDatabases(sourceDBs)
+	closeDatabases(targetDBs)
+
+	if err := os.Rename(dbDir, fmt.Sprintf("%s.%s.bak", dbDir, source)); err != nil {
+		return err
+	}
+	return os.Rename(targetDir, dbDir)
+}
+
+func openAppDatabases(dbDir string, backend db.Backend) ([]db.Database, error) {
+	metaDB, stateDB, chainDB, err := consensus.OpenDatabases(dbDir, backend)
+	if err != nil {
+		return nil, err
+	}
+	return []db.Database{metaDB, stateDB, chainDB}, nil
+}
+
+func closeDatabases(databases []db.Database) {
+	for _, database := range databases {
+		database.Close()
+	}
+}
+
+func (node *LiquidNode) addMigrateDBCommand() {
+	var target string
+
+	cmd := &cobra.Command{
+		Use:   "migrate_db --target <backend>",
+		Short: "Copy app databases to another backend",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := node.ParseConfig()
+			if err != nil {
+				return fmt.Errorf("Failed to parse config: %v", err)
+			}
+			source := appDBBackend()
+			if err := MigrateDB(appDBDir(conf), source, db.Backend(target)); err != nil {
+				return fmt.Errorf("Failed to migrate database: %v", err)
+			}
+			fmt.Printf("Migrated app databases from %s to %s, set %s = \"%s\" in config.toml before start\n", source, target, appDBBackendFlag, target)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&target, "target", string(db.LevelDBBackend), "target database backend")
+	addAppDBBackendFlag(cmd)
+	node.command.AddCommand(cmd)
+}
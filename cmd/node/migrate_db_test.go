@@ -0,0 +1,43 @@
+package node
+
+import (
+	"os"
+	"testing"
+
+	"github.com/QuoineFinancial/liquid-chain/consensus"
+	"github.com/QuoineFinancial/liquid-chain/db"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+func TestMigrateDB(t *testing.T) {
+	dbDir := "./tmp-migrate-db"
+	defer os.RemoveAll(dbDir)
+	defer os.RemoveAll(dbDir + ".rocksdb.bak")
+
+	app := consensus.NewApp(dbDir, db.RocksDBBackend)
+	app.InitChain(types.RequestInitChain{ChainId: "migrate-test"})
+	genesisHash := app.Meta.BlockHeightToBlockHash(0)
+	app.Close()
+
+	if err := MigrateDB(dbDir, db.RocksDBBackend, db.RocksDBBackend); err == nil {
+		t.Error("MigrateDB accepts same backend")
+	}
+	if err := MigrateDB(dbDir, db.RocksDBBackend, db.LevelDBBackend); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dbDir + ".rocksdb.bak"); err != nil {
+		t.Errorf("Source database is not kept: %v", err)
+	}
+
+	app = consensus.NewApp(dbDir, db.LevelDBBackend)
+	defer app.Close()
+	if got := app.Meta.ChainID(); got != "migrate-test" {
+		t.Errorf("ChainID() = %s, want migrate-test", got)
+	}
+	if got := app.Meta.BlockHeightToBlockHash(0); got != genesisHash {
+		t.Errorf("Genesis hash = %s, want %s", got, genesisHash)
+	}
+	if _, err := app.Chain.GetBlock(genesisHash); err != nil {
+		t.Errorf("Genesis block is not migrated: %v", err)
+	}
+}
@@ -1,14 +1,43 @@
 package node
 
 import (
+	"path/filepath"
+
 	"github.com/QuoineFinancial/liquid-chain/api"
 	"github.com/QuoineFinancial/liquid-chain/consensus"
+	"github.com/QuoineFinancial/liquid-chain/db"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/tendermint/tendermint/cmd/tendermint/commands"
+	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/libs/cli"
 	tmNode "github.com/tendermint/tendermint/node"
 )
 
+// Flags of app, also read from config.toml
+const (
+	// appDBBackendFlag selects database backend of app
+	appDBBackendFlag = "app_db_backend"
+
+	// pruningFlag selects which historical states are kept: archive | recent | everything
+	pruningFlag           = "pruning"
+	pruningKeepRecentFlag = "pruning_keep_recent"
+	pruningIntervalFlag   = "pruning_interval"
+
+	// snapshotIntervalFlag sets number of heights between two state sync snapshots, 0 disables snapshots
+	snapshotIntervalFlag   = "snapshot_interval"
+	snapshotKeepRecentFlag = "snapshot_keep_recent"
+
+	// legacyTxCutoffFlag overrides height of genesis since which legacy transactions are rejected
+	legacyTxCutoffFlag = "legacy_tx_cutoff_height"
+
+	// betaGasPolicyFlag overrides height of genesis since which host functions are charged by beta gas policy
+	betaGasPolicyFlag = "beta_gas_policy_height"
+
+	// contractUpgradeFlag overrides height of genesis since which contracts can be upgraded by transactions
+	contractUpgradeFlag = "contract_upgrade_height"
+)
+
 // LiquidNode is the space where app and command lives
 type LiquidNode struct {
 	rootDir            string
@@ -23,11 +52,14 @@ type LiquidNode struct {
 func New(rootDir string, gasContractAddress string) *LiquidNode {
 	liquidNode := LiquidNode{
 		rootDir:            rootDir,
-		command:            commands.RootCmd,
 		gasContractAddress: gasContractAddress,
+		command:            commands.RootCmd,
 	}
 	liquidNode.addDefaultCommands()
 	liquidNode.addStartNodeCommand()
+	liquidNode.addMigrateDBCommand()
+	liquidNode.addExportStateCommand()
+	liquidNode.addImportStateCommand()
 	return &liquidNode
 }
 
@@ -36,7 +68,7 @@ func (node *LiquidNode) addDefaultCommands() {
 		commands.GenValidatorCmd,
 		commands.InitFilesCmd,
 		commands.ProbeUpnpCmd,
-		commands.LiteCmd,
+		commands.LightCmd,
 		commands.ReplayCmd,
 		commands.ReplayConsoleCmd,
 		commands.ResetAllCmd,
@@ -58,3 +90,63 @@ func (node *LiquidNode) Execute() {
 		panic(err)
 	}
 }
+
+func addAppDBBackendFlag(cmd *cobra.Command) {
+	cmd.Flags().String(appDBBackendFlag, string(db.DefaultBackend), "database backend of app: rocksdb | goleveldb")
+}
+
+// appDBBackend returns database backend of app from flag or config
+func appDBBackend() db.Backend {
+	if backend := viper.GetString(appDBBackendFlag); len(backend) > 0 {
+		return db.Backend(backend)
+	}
+	return db.DefaultBackend
+}
+
+func addPruningFlags(cmd *cobra.Command) {
+	cmd.Flags().String(pruningFlag, consensus.PruningArchive, "pruning mode of historical states: archive | recent | everything")
+	cmd.Flags().Uint64(pruningKeepRecentFlag, 0, "number of latest heights whose states are kept in recent pruning mode")
+	cmd.Flags().Uint64(pruningIntervalFlag, consensus.DefaultPruningInterval, "number of heights between two prunings, each pruning scans the whole state database")
+}
+
+// appPruningOptions returns pruning options of app from flags or config
+func appPruningOptions() (consensus.PruningOptions, error) {
+	mode := viper.GetString(pruningFlag)
+	if len(mode) == 0 {
+		mode = consensus.PruningArchive
+	}
+	return consensus.NewPruningOptions(mode, viper.GetUint64(pruningKeepRecentFlag), viper.GetUint64(pruningIntervalFlag))
+}
+
+func addSnapshotFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64(snapshotIntervalFlag, 0, "number of heights between two state sync snapshots, 0 disables snapshots")
+	cmd.Flags().Uint64(snapshotKeepRecentFlag, 2, "number of latest snapshots kept, 0 keeps all snapshots")
+}
+
+// appSnapshotOptions returns snapshot options of app from flags or config
+func appSnapshotOptions() consensus.SnapshotOptions {
+	return consensus.SnapshotOptions{
+		Interval:   viper.GetUint64(snapshotIntervalFlag),
+		KeepRecent: viper.GetUint64(snapshotKeepRecentFlag),
+	}
+}
+
+func addUpgradeFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64(legacyTxCutoffFlag, 0, "height since which legacy transactions without chain ID are rejected, 0 keeps height of genesis")
+	cmd.Flags().Uint64(betaGasPolicyFlag, 0, "height since which host functions are charged by beta gas policy, 0 keeps height of genesis")
+	cmd.Flags().Uint64(contractUpgradeFlag, 0, "height since which contracts can be upgraded by transactions, 0 keeps height of genesis")
+}
+
+// appUpgradeHeights returns upgrade heights of app from flags or config, overriding ones of genesis
+func appUpgradeHeights() consensus.UpgradeHeights {
+	return consensus.UpgradeHeights{
+		LegacyTxCutoff:  viper.GetUint64(legacyTxCutoffFlag),
+		BetaGasPolicy:   viper.GetUint64(betaGasPolicyFlag),
+		ContractUpgrade: viper.GetUint64(contractUpgradeFlag),
+	}
+}
+
+// appDBDir returns the directory of app databases
+func appDBDir(config *config.Config) string {
+	return filepath.Join(config.DBDir(), "liquid")
+}
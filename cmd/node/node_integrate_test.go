@@ -100,7 +100,7 @@ func getDeployLiquidTokenTx(t *testing.T, nonce uint64) string {
 		GasPrice:  1,
 		Signature: nil,
 	}
-	dataToSign := crypto.GetSigHash(deployTx)
+	dataToSign := crypto.GetSigHash(deployTx, "")
 	deployTx.Signature = crypto.Sign(privateKey, dataToSign[:])
 	rawTx, _ := deployTx.Encode()
 	serializedTx := base64.StdEncoding.EncodeToString(rawTx)
@@ -112,7 +112,7 @@ func TestBroadcastTx(t *testing.T) {
 	defer ts.stopNode()
 	ts.startNode()
 
-	api := api.NewAPI(":5555", "tcp://localhost:26657", ts.node.rootDir, *ts.node.app.Meta, *ts.node.app.State, *ts.node.app.Chain)
+	api := api.NewAPI(":5555", "tcp://localhost:26657", *ts.node.app.Meta, *ts.node.app.State, *ts.node.app.Chain)
 
 	router := api.Router
 
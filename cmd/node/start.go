@@ -3,7 +3,6 @@ package node
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/QuoineFinancial/liquid-chain/api"
 	"github.com/QuoineFinancial/liquid-chain/consensus"
@@ -21,7 +20,17 @@ import (
 )
 
 func (node *LiquidNode) newTendermintNode(config *config.Config, logger log.Logger) (*tmNode.Node, error) {
-	node.app = consensus.NewApp(filepath.Join(config.DBDir(), "liquid"), node.gasContractAddress)
+	pruning, err := appPruningOptions()
+	if err != nil {
+		return nil, err
+	}
+	node.app = consensus.NewApp(appDBDir(config), appDBBackend())
+	node.app.SetPruningOptions(pruning)
+	node.app.SetSnapshotOptions(appSnapshotOptions())
+	node.app.SetUpgradeHeights(appUpgradeHeights())
+	if err := node.app.SetLegacyGasContractAddress(node.gasContractAddress); err != nil {
+		return nil, err
+	}
 	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load or gen node key %s: %w", config.NodeKeyFile(), err)
@@ -88,7 +97,7 @@ func (node *LiquidNode) Start(conf *config.Config, apiFlag bool) error {
 	}
 
 	if apiFlag {
-		node.chainAPI = api.NewAPI(":5555", "tcp://localhost:26657", node.rootDir, *node.app.Meta, *node.app.State, *node.app.Chain)
+		node.chainAPI = api.NewAPI(":5555", "tcp://localhost:26657", *node.app.Meta, *node.app.State, *node.app.Chain)
 		err := node.chainAPI.Serve()
 		if err != nil {
 			return err
@@ -107,6 +116,10 @@ func (node *LiquidNode) Stop() {
 	if node.tmNode.IsRunning() {
 		_ = node.tmNode.Stop() // TODO: Properly handle error
 	}
+
+	if node.app != nil {
+		node.app.Close()
+	}
 }
 
 func (node *LiquidNode) addStartNodeCommand() {
@@ -131,6 +144,10 @@ func (node *LiquidNode) addStartNodeCommand() {
 		},
 	}
 	cmd.PersistentFlags().BoolVarP(&apiFlag, "api", "a", false, "start api")
+	addAppDBBackendFlag(cmd)
+	addPruningFlags(cmd)
+	addSnapshotFlags(cmd)
+	addUpgradeFlags(cmd)
 
 	commands.AddNodeFlags(cmd)
 	node.command.AddCommand(cmd)
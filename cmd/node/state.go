@@ -0,0 +1,83 @@
+package node
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/QuoineFinancial/liquid-chain/consensus"
+	"github.com/spf13/cobra"
+)
+
+func (node *LiquidNode) openApp() (*consensus.App, error) {
+	conf, err := node.ParseConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse config: %v", err)
+	}
+	return consensus.NewApp(appDBDir(conf), appDBBackend()), nil
+}
+
+func (node *LiquidNode) addExportStateCommand() {
+	var height int64
+	var output string
+
+	cmd := &cobra.Command{
+		Use:     "export_state --height <height> --output <file>",
+		Aliases: []string{"export-state"},
+		Short:   "Export accounts, contracts and storage of state at height",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := node.openApp()
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			header, err := app.ExportState(file, height)
+			if err != nil {
+				return fmt.Errorf("Failed to export state: %v", err)
+			}
+			fmt.Printf("Exported state at height %d with state root %s to %s\n", header.Height, header.StateRoot, output)
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&height, "height", 0, "height of exported state, latest height by default")
+	cmd.Flags().StringVar(&output, "output", "state.json", "exported state file")
+	addAppDBBackendFlag(cmd)
+	node.command.AddCommand(cmd)
+}
+
+func (node *LiquidNode) addImportStateCommand() {
+	var input string
+
+	cmd := &cobra.Command{
+		Use:     "import_state --input <file>",
+		Aliases: []string{"import-state"},
+		Short:   "Import exported state and verify its state root",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := node.openApp()
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			file, err := os.Open(input)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			header, err := app.ImportState(file)
+			if err != nil {
+				return fmt.Errorf("Failed to import state: %v", err)
+			}
+			fmt.Printf("Imported state at height %d with state root %s\n", header.Height, header.StateRoot)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&input, "input", "state.json", "exported state file")
+	addAppDBBackendFlag(cmd)
+	node.command.AddCommand(cmd)
+}
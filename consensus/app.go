@@ -2,9 +2,9 @@ package consensus
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/QuoineFinancial/liquid-chain/constant"
@@ -31,8 +31,35 @@ type App struct {
 	State *storage.StateStorage
 	Chain *storage.ChainStorage
 
+	stateDB *db.BufferedDatabase
+	chainDB *db.BufferedDatabase
+
+	// stateStore is the database under stateDB, pruned in background
+	stateStore   db.Database
+	persistMutex sync.Mutex
+
+	// chainMetas are stored along with metas of the next persisted block
+	chainMetas storage.ChainMetas
+	// contractUpgrades are upgrades by transactions of the executing block, indexed when it is persisted
+	contractUpgrades []storage.ContractUpgrade
+
 	gasStation         gas.Station
 	gasContractAddress string
+	minGasPrice        uint32
+
+	pruning PruningOptions
+	pruner  *heightWorker
+
+	snapshots         SnapshotOptions
+	snapshotter       *heightWorker
+	snapshotDir       string
+	snapshotChunkSize int
+	// snapshotHeight is height of state being written to a snapshot, guarded by persistMutex
+	snapshotHeight uint64
+	restore        *snapshotRestore
+
+	upgrades         UpgradeHeights
+	upgradeOverrides UpgradeHeights
 }
 
 // We use this code to communicate with Tendermint
@@ -56,27 +83,129 @@ func appHashToBlockHash(appHash []byte) common.Hash {
 	return common.BytesToHash(appHash)
 }
 
-// NewApp initializes a new app
-func NewApp(dbDir string, gasContractAddress string) *App {
+// mustGetBlockByAppHash returns block of appHash.
+// Empty appHash refers to genesis block, which might have pre-allocated state
+func (app *App) mustGetBlockByAppHash(appHash []byte) *crypto.Block {
+	blockHash := appHashToBlockHash(appHash)
+	if blockHash == common.EmptyHash {
+		blockHash = app.Meta.BlockHeightToBlockHash(crypto.GenesisBlock.Height)
+	}
+	return app.Chain.MustGetBlock(blockHash)
+}
+
+// OpenDatabases opens meta, state and chain databases of app in dbDir
+func OpenDatabases(dbDir string, backend db.Backend) (metaDB, stateDB, chainDB db.Database, err error) {
 	if _, err := os.Stat(dbDir); os.IsNotExist(err) {
 		os.Mkdir(dbDir, os.ModePerm)
 	}
+	if metaDB, err = db.NewDatabase(backend, filepath.Join(dbDir, metaDBDir)); err != nil {
+		return nil, nil, nil, err
+	}
+	if stateDB, err = db.NewDatabase(backend, filepath.Join(dbDir, stateDBDir)); err != nil {
+		return nil, nil, nil, err
+	}
+	if chainDB, err = db.NewDatabase(backend, filepath.Join(dbDir, chainDBDir)); err != nil {
+		return nil, nil, nil, err
+	}
+	return metaDB, stateDB, chainDB, nil
+}
+
+// NewApp initializes a new app with databases of given backend
+func NewApp(dbDir string, backend db.Backend) *App {
+	metaDB, rawStateDB, rawChainDB, err := OpenDatabases(dbDir, backend)
+	if err != nil {
+		panic(err)
+	}
+	// State and chain writes are buffered until block commit
+	stateDB := db.NewBufferedDatabase(rawStateDB)
+	chainDB := db.NewBufferedDatabase(rawChainDB)
 	app := &App{
-		Meta:               storage.NewMetaStorage(db.NewRocksDB(filepath.Join(dbDir, metaDBDir))),
-		State:              storage.NewStateStorage(db.NewRocksDB(filepath.Join(dbDir, stateDBDir))),
-		Chain:              storage.NewChainStorage(db.NewRocksDB(filepath.Join(dbDir, chainDBDir))),
-		gasContractAddress: gasContractAddress,
+		Meta:              storage.NewMetaStorage(metaDB),
+		State:             storage.NewStateStorage(stateDB),
+		Chain:             storage.NewChainStorage(chainDB),
+		stateDB:           stateDB,
+		chainDB:           chainDB,
+		stateStore:        rawStateDB,
+		snapshotDir:       filepath.Join(dbDir, snapshotsDir),
+		snapshotChunkSize: defaultSnapshotChunkSize,
+	}
+	if err := app.recover(); err != nil {
+		panic(err)
+	}
+	app.chainMetas = app.Meta.ChainMetas()
+
+	// Chain which is not initialized yet runs with default app state until InitChain
+	appState, err := DecodeGenesisAppState(app.chainMetas.GenesisAppState)
+	if err != nil {
+		panic(err)
 	}
-	app.SetGasStation(gas.NewFreeStation(app))
+	app.loadGenesisAppState(appState)
 	return app
 }
 
+// InitChain stores the chain ID and app state from genesis
+func (app *App) InitChain(req abciTypes.RequestInitChain) abciTypes.ResponseInitChain {
+	appState, err := DecodeGenesisAppState(req.AppStateBytes)
+	if err != nil {
+		panic(err)
+	}
+	rawAppState, err := appState.Encode()
+	if err != nil {
+		panic(err)
+	}
+
+	app.chainMetas = storage.ChainMetas{ChainID: req.ChainId, GenesisAppState: rawAppState}
+	if err := app.allocateGenesis(appState); err != nil {
+		panic(err)
+	}
+	app.loadGenesisAppState(appState)
+	return abciTypes.ResponseInitChain{}
+}
+
+// SetLegacyGasContractAddress sets gas contract of chains initialized before app state was stored in meta,
+// which used to be given by GAS_CONTRACT_ADDRESS. Chains with stored app state ignore it
+func (app *App) SetLegacyGasContractAddress(address string) error {
+	if len(app.chainMetas.GenesisAppState) > 0 || len(address) == 0 {
+		return nil
+	}
+	rawAppState, err := GenesisAppState{GasContractAddress: address}.Encode()
+	if err != nil {
+		return err
+	}
+	appState, err := DecodeGenesisAppState(rawAppState)
+	if err != nil {
+		return err
+	}
+	app.loadGenesisAppState(appState)
+	return nil
+}
+
+func (app *App) loadGenesisAppState(appState *GenesisAppState) {
+	app.gasContractAddress = appState.GasContractAddress
+	app.minGasPrice = appState.MinGasPrice
+	app.upgrades = appState.Upgrades.override(app.upgradeOverrides)
+	switch appState.GasStation {
+	case GasStationLiquid:
+		address, err := crypto.AddressFromString(appState.GasContractAddress)
+		if err != nil {
+			panic(err)
+		}
+		app.SetGasStation(gas.NewLiquidStation(app, address))
+	default:
+		app.SetGasStation(gas.NewFreeStation(app))
+	}
+}
+
 // BeginBlock begins new block
 func (app *App) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.ResponseBeginBlock {
-	lastBlockHash := appHashToBlockHash(req.Header.AppHash)
-	previousBlock := app.Chain.MustGetBlock(lastBlockHash)
+	// Chains initialized before chain ID was stored learn it from block header, it is stored on commit
+	if len(app.chainMetas.ChainID) == 0 {
+		app.chainMetas.ChainID = req.Header.ChainID
+	}
+	previousBlock := app.mustGetBlockByAppHash(req.Header.AppHash)
 	app.State.MustLoadState(previousBlock)
 	app.Chain.ComposeBlock(previousBlock, req.Header.Time)
+	app.contractUpgrades = nil
 	for app.gasStation.Switch() {
 	}
 	return abciTypes.ResponseBeginBlock{}
@@ -85,6 +214,10 @@ func (app *App) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.ResponseBe
 // Info returns application chain info
 func (app *App) Info(req abciTypes.RequestInfo) (resInfo abciTypes.ResponseInfo) {
 	lastBlockHeight := app.Meta.LatestBlockHeight()
+	if lastBlockHeight == crypto.GenesisBlock.Height {
+		// Tendermint expects the empty app_hash of genesis.json
+		return abciTypes.ResponseInfo{LastBlockAppHash: []byte{}}
+	}
 	lastBlockHash := app.Meta.BlockHeightToBlockHash(lastBlockHeight)
 	return abciTypes.ResponseInfo{
 		LastBlockHeight:  int64(lastBlockHeight),
@@ -146,17 +279,33 @@ func (app *App) DeliverTx(req abciTypes.RequestDeliverTx) abciTypes.ResponseDeli
 // Commit returns the state root of application storage. Called once all block processing is complete
 func (app *App) Commit() abciTypes.ResponseCommit {
 	blockHash := app.Chain.Commit(app.State.Commit())
-	if err := app.Meta.StoreBlockMetas(app.Chain.CurrentBlock); err != nil {
-		log.Println("unable to store index for block", blockHash)
+	if err := app.persist(app.Chain.CurrentBlock); err != nil {
+		panic(err)
 	}
+	app.schedulePruning(app.Chain.CurrentBlock.Height)
+	app.scheduleSnapshot(app.Chain.CurrentBlock.Height)
 	return abciTypes.ResponseCommit{Data: blockHashToAppHash(blockHash)}
 }
 
+// Close closes all databases of app
+func (app *App) Close() {
+	app.pruner.stop()
+	app.snapshotter.stop()
+	app.Meta.Close()
+	app.State.Close()
+	app.Chain.Close()
+}
+
 // SetGasStation active the gas station
 func (app *App) SetGasStation(gasStation gas.Station) {
 	app.gasStation = gasStation
 }
 
+// GetMinGasPrice returns minimum gas price from genesis
+func (app *App) GetMinGasPrice() uint32 {
+	return app.minGasPrice
+}
+
 // GetGasContractToken designated
 func (app *App) GetGasContractToken() gas.Token {
 	if len(app.gasContractAddress) > 0 {
@@ -8,6 +8,8 @@ import (
 	"github.com/QuoineFinancial/liquid-chain/util"
 )
 
+const testChainID = "liquid-test"
+
 func (tr TestResource) getSenderWithNonce(nonce int) (crypto.TxSender, ed25519.PrivateKey) {
 	seed := make([]byte, 32)
 	privateKey := ed25519.NewKeyFromSeed(seed)
@@ -32,7 +34,7 @@ func (tr TestResource) getDeployTx(nonce int) *crypto.Transaction {
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, testChainID)
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -52,7 +54,7 @@ func (tr TestResource) getInvokeTx(nonce int) *crypto.Transaction {
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, testChainID)
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -106,7 +108,7 @@ func (tr TestResource) getInvalidNonceTx(nonce int) *crypto.Transaction {
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, testChainID)
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -126,7 +128,7 @@ func (tr TestResource) getInvalidGasPriceTx(nonce int) *crypto.Transaction {
 		GasLimit: 0,
 		GasPrice: 0,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, testChainID)
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -146,7 +148,7 @@ func (tr TestResource) getInvokeNilContractTx(nonce int) *crypto.Transaction {
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, testChainID)
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -166,7 +168,7 @@ func (tr TestResource) getInvokeNonContractTx(nonce int) *crypto.Transaction {
 		GasLimit: 0,
 		GasPrice: 1,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, testChainID)
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
@@ -186,7 +188,27 @@ func (tr TestResource) getInvalidSerializedTx(nonce int) *crypto.Transaction {
 		GasLimit: 0,
 		GasPrice: 0,
 	}
-	dataToSign := crypto.GetSigHash(tx)
+	dataToSign := crypto.GetSigHash(tx, testChainID)
+	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
+	return tx
+}
+
+func (tr TestResource) getChainIDInvokeTx(nonce int, version uint16, chainID string) *crypto.Transaction {
+	sender, privateKey := tr.getSenderWithNonce(nonce)
+	senderAddress := crypto.AddressFromPubKey(sender.PublicKey)
+	data, err := util.BuildInvokeTxPayload("../test/testdata/liquid-token-abi.json", "mint", []string{"1000"})
+	if err != nil {
+		panic(err)
+	}
+	tx := &crypto.Transaction{
+		Version:  version,
+		Sender:   &sender,
+		Payload:  data,
+		Receiver: crypto.NewDeploymentAddress(senderAddress, 0),
+		GasLimit: 0,
+		GasPrice: 1,
+	}
+	dataToSign := crypto.GetSigHash(tx, chainID)
 	tx.Signature = crypto.Sign(privateKey, dataToSign.Bytes())
 	return tx
 }
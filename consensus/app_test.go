@@ -2,28 +2,39 @@ package consensus
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"math/rand"
+	"io/ioutil"
 	"os"
-	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/abi"
 	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/QuoineFinancial/liquid-chain/constant"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/db"
+	"github.com/QuoineFinancial/liquid-chain/gas"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+	"github.com/QuoineFinancial/liquid-chain/trie"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/tendermint/tendermint/abci/types"
+	tmCrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 )
 
 func newAppTestResource() *TestResource {
-	rand.Seed(time.Now().UTC().UnixNano())
-	dbDir := "./tmp" + strconv.Itoa(rand.Intn(10000)) + "/"
-	if err := os.MkdirAll(dbDir, os.ModePerm); err != nil {
+	dbDir, err := ioutil.TempDir("", "liquid-consensus")
+	if err != nil {
 		panic(err)
 	}
-	app := NewApp(dbDir, "")
+	app := NewApp(dbDir, db.DefaultBackend)
+	app.InitChain(types.RequestInitChain{ChainId: testChainID})
 	if err := app.State.LoadState(&crypto.GenesisBlock); err != nil {
 		panic(err)
 	}
@@ -31,9 +42,7 @@ func newAppTestResource() *TestResource {
 }
 
 func TestNewApp(t *testing.T) {
-	rand.Seed(time.Now().UTC().UnixNano())
-	dbDir := "./tmp" + strconv.Itoa(rand.Intn(10000)) + "/"
-	err := os.MkdirAll(dbDir, os.ModePerm)
+	dbDir, err := ioutil.TempDir("", "liquid-consensus")
 	if err != nil {
 		panic(err)
 	}
@@ -41,10 +50,120 @@ func TestNewApp(t *testing.T) {
 		_ = os.RemoveAll(dbDir)
 	}()
 
+	app := NewApp(dbDir, db.DefaultBackend)
+	assert.NotNil(t, app)
+	assert.IsType(t, &gas.FreeStation{}, app.gasStation)
+	assert.Equal(t, gas.DefaultMinimumGasPrice, app.GetMinGasPrice())
+
+	// Invalid app state is rejected before anything is stored
 	gasContractAddress := "LACWIGXH6CZCRRHFSK2F4BINXGUGUS2FSX5GSYG3RMP5T55EV72DHAJ7"
+	appState := fmt.Sprintf(`{"gasContractAddress": "%s", "gasStation": "liquid"}`, gasContractAddress)
+	_, err = DecodeGenesisAppState([]byte(appState))
+	assert.EqualError(t, err, fmt.Sprintf("Gas contract %s not found in genesis contracts", gasContractAddress))
+	assert.Panics(t, func() {
+		app.InitChain(types.RequestInitChain{ChainId: testChainID, AppStateBytes: []byte(appState)})
+	})
+	assert.Empty(t, app.Meta.ChainID())
+	assert.Empty(t, app.Meta.GenesisAppState())
 
-	app := NewApp(dbDir, gasContractAddress)
-	assert.NotNil(t, app)
+	// Chain without stored app state uses gas contract from environment
+	assert.Error(t, app.SetLegacyGasContractAddress("invalid"))
+	assert.NoError(t, app.SetLegacyGasContractAddress(gasContractAddress))
+	assert.Equal(t, gasContractAddress, app.gasContractAddress)
+
+	app.InitChain(types.RequestInitChain{ChainId: testChainID})
+	assert.NoError(t, app.SetLegacyGasContractAddress(gasContractAddress))
+	assert.Empty(t, app.gasContractAddress)
+}
+
+func TestApp_InitChain(t *testing.T) {
+	gasContractAddress := "LACWIGXH6CZCRRHFSK2F4BINXGUGUS2FSX5GSYG3RMP5T55EV72DHAJ7"
+
+	t.Run("Should store genesis app state", func(t *testing.T) {
+		tr := newAppTestResource()
+		defer tr.cleanData()
+
+		appState := fmt.Sprintf(`{"gasContractAddress": "%s", "minGasPrice": 20, "upgrades": {"legacyTxCutoff": 100}}`, gasContractAddress)
+		tr.app.InitChain(types.RequestInitChain{ChainId: testChainID, AppStateBytes: []byte(appState)})
+		assert.Equal(t, gasContractAddress, tr.app.gasContractAddress)
+		assert.Equal(t, uint32(20), tr.app.GetMinGasPrice())
+		assert.Equal(t, UpgradeHeights{LegacyTxCutoff: 100}, tr.app.upgrades)
+		assert.IsType(t, &gas.FreeStation{}, tr.app.gasStation)
+
+		// Reopened app loads app state from meta, configured upgrade heights override genesis
+		tr.app.Close()
+		app := NewApp(tr.dbDir, db.DefaultBackend)
+		assert.Equal(t, gasContractAddress, app.gasContractAddress)
+		assert.Equal(t, uint32(20), app.GetMinGasPrice())
+		assert.Equal(t, testChainID, app.Meta.ChainID())
+		assert.Equal(t, UpgradeHeights{LegacyTxCutoff: 100}, app.upgrades)
+		app.SetUpgradeHeights(UpgradeHeights{LegacyTxCutoff: 50})
+		assert.Equal(t, UpgradeHeights{LegacyTxCutoff: 50}, app.upgrades)
+	})
+
+	t.Run("Should allocate genesis contracts", func(t *testing.T) {
+		tr := newAppTestResource()
+		defer tr.cleanData()
+		app := tr.app
+
+		sender, _ := tr.getSenderWithNonce(0)
+		senderAddress := crypto.AddressFromPubKey(sender.PublicKey)
+		contractAddress := crypto.NewDeploymentAddress(senderAddress, 0)
+		header, _ := ioutil.ReadFile("../test/testdata/liquid-token-abi.json")
+		code, _ := ioutil.ReadFile("../test/testdata/liquid-token.wasm")
+		balance := make([]byte, 8)
+		binary.LittleEndian.PutUint64(balance, 500)
+
+		appState, _ := json.Marshal(GenesisAppState{
+			GasContractAddress: contractAddress.String(),
+			Contracts: []*GenesisContract{{
+				Address: contractAddress.String(),
+				Creator: senderAddress.String(),
+				Header:  header,
+				Code:    hex.EncodeToString(code),
+				Storage: map[string]string{hex.EncodeToString(senderAddress[:]): hex.EncodeToString(balance)},
+			}},
+		})
+		app.InitChain(types.RequestInitChain{ChainId: testChainID, AppStateBytes: appState})
+		assert.Equal(t, types.ResponseInfo{LastBlockAppHash: []byte{}}, app.Info(types.RequestInfo{}))
+
+		genesisBlock, err := app.Chain.GetBlock(app.Meta.BlockHeightToBlockHash(0))
+		assert.NoError(t, err)
+		assert.NotEqual(t, common.EmptyHash, genesisBlock.StateRoot)
+
+		// Genesis state is loaded for the first block, gas token is live
+		app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{ChainID: testChainID, Height: 1, AppHash: []byte{}}})
+		assert.Equal(t, genesisBlock.StateRoot, app.State.GetBlock().StateRoot)
+		assert.IsType(t, &gas.LiquidStation{}, app.gasStation)
+
+		account, err := app.State.GetAccount(contractAddress)
+		assert.NoError(t, err)
+		assert.Equal(t, senderAddress, account.Creator)
+		value, _ := account.GetStorage(senderAddress[:])
+		assert.Equal(t, balance, value)
+
+		rawTx, _ := tr.getInvokeTx(0).Encode()
+		assert.Equal(t, "Invalid gas price", app.CheckTx(types.RequestCheckTx{Tx: rawTx}).Log)
+	})
+
+	t.Run("Should panic with invalid app state", func(t *testing.T) {
+		tr := newAppTestResource()
+		defer tr.cleanData()
+
+		for _, appState := range []string{
+			`{"contracts": [{"address": "invalid"}]}`,
+			fmt.Sprintf(`{"contracts": [{"address": "%s", "creator": "%s", "header": {}, "code": "zz"}]}`, gasContractAddress, gasContractAddress),
+			`{"gasContractAddress": 1}`,
+			`{"gasContractAddress": "invalid"}`,
+			`{"gasStation": "unknown"}`,
+			`{"gasStation": "liquid"}`,
+			fmt.Sprintf(`{"gasContractAddress": "%s", "gasStation": "liquid"}`, gasContractAddress),
+		} {
+			assert.Panics(t, func() {
+				tr.app.InitChain(types.RequestInitChain{ChainId: testChainID, AppStateBytes: []byte(appState)})
+			}, appState)
+		}
+	})
 }
 
 func TestApp_BeginBlock(t *testing.T) {
@@ -57,7 +176,7 @@ func TestApp_BeginBlock(t *testing.T) {
 		previousBlockHash := common.EmptyHash.Bytes()
 		stateRootHash := tr.app.State.Commit()
 
-		req := types.RequestBeginBlock{Header: types.Header{Height: reqHeight, AppHash: previousBlockHash}}
+		req := types.RequestBeginBlock{Header: tmproto.Header{Height: reqHeight, AppHash: previousBlockHash}}
 		got := app.BeginBlock(req)
 		want := types.ResponseBeginBlock{}
 		if !cmp.Equal(got, want) {
@@ -86,7 +205,7 @@ func TestApp_BeginBlock(t *testing.T) {
 		assert.NotNil(t, app.State)
 		assert.Equal(t, uint64(0), app.State.GetBlock().Height)
 
-		req := types.RequestBeginBlock{Header: types.Header{Height: reqHeight, AppHash: blockHash.Bytes()}}
+		req := types.RequestBeginBlock{Header: tmproto.Header{Height: reqHeight, AppHash: blockHash.Bytes()}}
 		got := app.BeginBlock(req)
 		want := types.ResponseBeginBlock{}
 		if !cmp.Equal(got, want) {
@@ -109,7 +228,7 @@ func TestApp_Info(t *testing.T) {
 		height := 2
 		stateRootHash := tr.app.State.Commit()
 		block := crypto.Block{Height: uint64(height), Time: uint64(time.Now().Unix()), Parent: common.EmptyHash, StateRoot: stateRootHash}
-		app.Meta.StoreBlockMetas(&block)
+		app.Meta.StoreBlockMetas(&block, storage.ChainMetas{}, nil)
 
 		got := app.Info(types.RequestInfo{})
 		// returns correct current state
@@ -130,7 +249,7 @@ func TestApp_CheckTx(t *testing.T) {
 	app := tr.app
 
 	app.BeginBlock(types.RequestBeginBlock{
-		Header: types.Header{
+		Header: tmproto.Header{
 			Height:  1,
 			Time:    time.Now(),
 			AppHash: []byte{},
@@ -164,6 +283,12 @@ func TestApp_CheckTx(t *testing.T) {
 		}, {
 			tx:                      tr.getInvokeNonContractTx(1),
 			expectedResponseCheckTx: types.ResponseCheckTx{Code: ResponseCodeNotOK, Log: "Invoke a non-contract account"},
+		}, {
+			tx:                      tr.getChainIDInvokeTx(1, crypto.TxVersionChainID, "liquid-other"),
+			expectedResponseCheckTx: types.ResponseCheckTx{Code: ResponseCodeNotOK, Log: "Invalid signature"},
+		}, {
+			tx:                      tr.getChainIDInvokeTx(1, 3, testChainID),
+			expectedResponseCheckTx: types.ResponseCheckTx{Code: ResponseCodeNotOK, Log: "tx version 3 not supported"},
 		}}
 
 		for i, checkTxTest := range checkTxTestTable {
@@ -177,13 +302,92 @@ func TestApp_CheckTx(t *testing.T) {
 	})
 }
 
+func TestApp_CheckTxChainID(t *testing.T) {
+	tr := newAppTestResource()
+	defer tr.cleanData()
+	app := tr.app
+
+	app.BeginBlock(types.RequestBeginBlock{
+		Header: tmproto.Header{
+			ChainID: testChainID,
+			Height:  1,
+			Time:    time.Now(),
+			AppHash: []byte{},
+		},
+	})
+	deployTx, _ := tr.getDeployTx(0).Encode()
+	app.DeliverTx(types.RequestDeliverTx{Tx: deployTx})
+	app.Commit()
+	assert.Equal(t, testChainID, app.Meta.ChainID())
+
+	for _, tx := range []*crypto.Transaction{
+		tr.getChainIDInvokeTx(1, crypto.TxVersionChainID, testChainID),
+		tr.getChainIDInvokeTx(1, crypto.TxVersionLegacy, ""),
+	} {
+		rawTx, _ := tx.Encode()
+		res := app.CheckTx(types.RequestCheckTx{Tx: rawTx})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+	}
+
+	t.Run("Legacy tx is rejected from cutoff height", func(t *testing.T) {
+		rawTx, _ := tr.getChainIDInvokeTx(1, crypto.TxVersionLegacy, "").Encode()
+		app.SetUpgradeHeights(UpgradeHeights{LegacyTxCutoff: 3})
+		assert.Equal(t, ResponseCodeOK, app.CheckTx(types.RequestCheckTx{Tx: rawTx}).Code)
+
+		app.SetUpgradeHeights(UpgradeHeights{LegacyTxCutoff: 2})
+		res := app.CheckTx(types.RequestCheckTx{Tx: rawTx})
+		assert.Equal(t, "tx version 1 not supported since height 2", res.Log)
+
+		rawTx, _ = tr.getChainIDInvokeTx(1, crypto.TxVersionChainID, testChainID).Encode()
+		assert.Equal(t, ResponseCodeOK, app.CheckTx(types.RequestCheckTx{Tx: rawTx}).Code)
+		app.SetUpgradeHeights(UpgradeHeights{})
+	})
+
+	t.Run("Chain ID is learnt from block header", func(t *testing.T) {
+		tr := newAppTestResource()
+		defer tr.cleanData()
+		// Chain initialized before chain ID was stored
+		tr.app.Close()
+		os.RemoveAll(tr.dbDir)
+		tr.app = NewApp(tr.dbDir, db.DefaultBackend)
+		tr.app.State.LoadState(&crypto.GenesisBlock)
+
+		rawTx, _ := tr.getChainIDInvokeTx(0, crypto.TxVersionChainID, testChainID).Encode()
+		res := tr.app.CheckTx(types.RequestCheckTx{Tx: rawTx})
+		assert.Equal(t, "Chain ID is not initialized", res.Log)
+
+		tr.app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{ChainID: testChainID, Height: 1, AppHash: []byte{}}})
+		assert.NotEqual(t, "Chain ID is not initialized", tr.app.CheckTx(types.RequestCheckTx{Tx: rawTx}).Log)
+		assert.Empty(t, tr.app.Meta.ChainID())
+
+		// Chain ID is stored with metas of the block
+		tr.app.Commit()
+		assert.Equal(t, testChainID, tr.app.Meta.ChainID())
+	})
+}
+
+func TestApp_GasPolicy(t *testing.T) {
+	tr := newAppTestResource()
+	defer tr.cleanData()
+	app := tr.app
+	assert.IsType(t, &gas.FreePolicy{}, app.gasPolicy())
+
+	app.SetGasStation(gas.NewLiquidStation(app, crypto.EmptyAddress))
+	app.SetUpgradeHeights(UpgradeHeights{BetaGasPolicy: 2})
+	assert.IsType(t, &gas.AlphaPolicy{}, app.gasPolicy())
+
+	app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{ChainID: testChainID, Height: 1, Time: time.Now(), AppHash: []byte{}}})
+	app.Commit()
+	assert.IsType(t, &gas.BetaPolicy{}, app.gasPolicy())
+}
+
 func TestApp_DeliverTx(t *testing.T) {
 	tr := newAppTestResource()
 	defer tr.cleanData()
 	app := tr.app
 
 	app.BeginBlock(types.RequestBeginBlock{
-		Header: types.Header{
+		Header: tmproto.Header{
 			Height:  1,
 			Time:    time.Now(),
 			AppHash: []byte{},
@@ -263,6 +467,405 @@ func TestApp_DeliverTx(t *testing.T) {
 	})
 }
 
+func TestApp_Recover(t *testing.T) {
+	tr := newAppTestResource()
+	defer tr.cleanData()
+	app := tr.app
+
+	appHash := []byte{}
+	for i, tx := range []*crypto.Transaction{tr.getDeployTx(0), tr.getInvokeTx(1)} {
+		app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{Height: int64(i + 1), Time: time.Unix(int64(i+1), 0), AppHash: appHash}})
+		rawTx, _ := tx.Encode()
+		app.DeliverTx(types.RequestDeliverTx{Tx: rawTx})
+		appHash = app.Commit().Data
+	}
+	latestBlock, _ := app.Chain.GetBlock(app.Meta.BlockHeightToBlockHash(2))
+
+	t.Run("Should keep fully stored height", func(t *testing.T) {
+		app.recover()
+		assert.Equal(t, uint64(2), app.Meta.LatestBlockHeight())
+	})
+
+	t.Run("Should roll back height of missing block", func(t *testing.T) {
+		app.Meta.StoreBlockMetas(&crypto.Block{Height: 3, Parent: latestBlock.Hash(), StateRoot: latestBlock.StateRoot}, storage.ChainMetas{}, nil)
+		assert.Equal(t, uint64(3), app.Meta.LatestBlockHeight())
+		app.recover()
+		assert.Equal(t, uint64(2), app.Meta.LatestBlockHeight())
+	})
+
+	t.Run("Should roll back height of missing state", func(t *testing.T) {
+		block := &crypto.Block{Height: 3, Parent: latestBlock.Hash(), StateRoot: common.HexToHash("01")}
+		rawBlock, _ := block.Encode()
+		app.Chain.Put(block.Hash().Bytes(), rawBlock)
+		app.Meta.StoreBlockMetas(block, storage.ChainMetas{}, nil)
+		app.recover()
+		assert.Equal(t, uint64(2), app.Meta.LatestBlockHeight())
+		assert.Equal(t, appHash, app.Info(types.RequestInfo{}).LastBlockAppHash)
+	})
+
+	t.Run("Should initialize chain again when genesis metas fail to store", func(t *testing.T) {
+		tr := newAppTestResource()
+		defer tr.cleanData()
+		tr.app.Close()
+		os.RemoveAll(tr.dbDir)
+		app := NewApp(tr.dbDir, db.DefaultBackend)
+
+		sender, _ := tr.getSenderWithNonce(0)
+		senderAddress := crypto.AddressFromPubKey(sender.PublicKey)
+		header, _ := ioutil.ReadFile("../test/testdata/liquid-token-abi.json")
+		code, _ := ioutil.ReadFile("../test/testdata/liquid-token.wasm")
+		contractAddress := crypto.NewDeploymentAddress(senderAddress, 0)
+		appState, _ := json.Marshal(GenesisAppState{Contracts: []*GenesisContract{{
+			Address: contractAddress.String(),
+			Creator: senderAddress.String(),
+			Header:  header,
+			Code:    hex.EncodeToString(code),
+		}}})
+		req := types.RequestInitChain{ChainId: testChainID, AppStateBytes: appState}
+
+		// Genesis state is flushed, but metas are lost
+		meta := app.Meta
+		app.Meta = storage.NewMetaStorage(&failingDatabase{db.NewMemoryDB()})
+		assert.Panics(t, func() { app.InitChain(req) })
+		app.Meta = meta
+		app.Close()
+
+		app = NewApp(tr.dbDir, db.DefaultBackend)
+		assert.Empty(t, app.Meta.ChainID())
+		assert.Equal(t, types.ResponseInfo{LastBlockAppHash: []byte{}}, app.Info(types.RequestInfo{}))
+		app.InitChain(req)
+		assert.Equal(t, testChainID, app.Meta.ChainID())
+		assert.NotEmpty(t, app.Meta.GenesisAppState())
+		assert.NotEqual(t, common.EmptyHash, app.Meta.BlockHeightToBlockHash(0))
+		app.Close()
+	})
+}
+
+// failingDatabase fails to write batches
+type failingDatabase struct {
+	*db.MemoryDB
+}
+
+func (database *failingDatabase) NewBatch() db.Batch {
+	return &failingBatch{database.MemoryDB.NewBatch()}
+}
+
+type failingBatch struct {
+	db.Batch
+}
+
+func (batch *failingBatch) Write() error {
+	return fmt.Errorf("disk failure")
+}
+
+func TestApp_PruneStates(t *testing.T) {
+	tr := newAppTestResource()
+	defer tr.cleanData()
+	app := tr.app
+	app.SetPruningOptions(PruningOptions{KeepRecent: 2, Interval: 2})
+
+	appHash := []byte{}
+	txs := []*crypto.Transaction{tr.getDeployTx(0), tr.getInvokeTx(1), tr.getInvokeTx(2), tr.getInvokeTx(3), tr.getInvokeTx(4)}
+	for i, tx := range txs {
+		app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{Height: int64(i + 1), Time: time.Unix(int64(i+1), 0), AppHash: appHash}})
+		rawTx, _ := tx.Encode()
+		app.DeliverTx(types.RequestDeliverTx{Tx: rawTx})
+		appHash = app.Commit().Data
+	}
+	// Pruning runs in background, stopping pruner waits for it
+	app.pruner.stop()
+	sender, _ := tr.getSenderWithNonce(0)
+	contractAddress := crypto.NewDeploymentAddress(crypto.AddressFromPubKey(sender.PublicKey), 0)
+
+	t.Run("Should remove states before kept heights", func(t *testing.T) {
+		assert.Equal(t, uint64(3), app.Meta.EarliestStateHeight())
+		for height := uint64(1); height < 3; height++ {
+			block, err := app.loadBlockAt(height)
+			assert.NoError(t, err)
+			_, err = trie.New(block.StateRoot, app.State.Database)
+			assert.Error(t, err)
+
+			_, _, err = app.loadStateAt(int64(height))
+			assert.Equal(t, storage.ErrStatePruned, err)
+		}
+		res := app.Query(types.RequestQuery{Path: "/account/" + contractAddress.String(), Height: 2})
+		assert.Equal(t, ResponseCodeNotOK, res.Code)
+		assert.Equal(t, "state pruned", res.Log)
+	})
+
+	t.Run("Should keep states of recent heights", func(t *testing.T) {
+		for height := uint64(3); height <= 5; height++ {
+			_, state, err := app.loadStateAt(int64(height))
+			assert.NoError(t, err)
+			contract, err := state.GetAccount(contractAddress)
+			assert.NoError(t, err)
+			_, err = contract.GetContract()
+			assert.NoError(t, err)
+			storageTrie, err := trie.New(contract.StorageHash, app.State.Database)
+			assert.NoError(t, err)
+			iterator := trie.NewIterator(storageTrie.NodeIterator(nil))
+			for iterator.Next() {
+			}
+			assert.NoError(t, iterator.Err)
+		}
+	})
+
+	t.Run("Should keep states retained after collecting", func(t *testing.T) {
+		latestBlock, _ := app.loadBlockAt(5)
+		pruning := storage.NewStatePruning(app.stateStore)
+		pruning.Collect()
+		assert.NoError(t, pruning.Retain([]common.Hash{latestBlock.StateRoot}))
+		_, err := pruning.Sweep()
+		assert.NoError(t, err)
+		_, state, err := app.loadStateAt(5)
+		assert.NoError(t, err)
+		_, err = state.GetAccount(contractAddress)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should keep all states in archive mode", func(t *testing.T) {
+		app.SetPruningOptions(PruningOptions{})
+		assert.NoError(t, app.pruneStates(10))
+		assert.Equal(t, uint64(3), app.Meta.EarliestStateHeight())
+	})
+}
+
+func TestApp_ExportImportState(t *testing.T) {
+	tr := newAppTestResource()
+	defer tr.cleanData()
+	app := tr.app
+
+	appHash := []byte{}
+	for i, tx := range []*crypto.Transaction{tr.getDeployTx(0), tr.getInvokeTx(1)} {
+		app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{Height: int64(i + 1), Time: time.Unix(int64(i+1), 0), AppHash: appHash}})
+		rawTx, _ := tx.Encode()
+		app.DeliverTx(types.RequestDeliverTx{Tx: rawTx})
+		appHash = app.Commit().Data
+	}
+	latestBlock, _ := app.loadBlockAt(2)
+
+	var exported bytes.Buffer
+	header, err := app.ExportState(&exported, 0)
+	assert.NoError(t, err)
+	rawBlock, _ := latestBlock.Encode()
+	rawTx, _ := tr.getInvokeTx(1).Encode()
+	assert.Equal(t, storage.StateExportHeader{
+		Version:      storage.StateExportVersion,
+		ChainID:      testChainID,
+		AppState:     app.Meta.GenesisAppState(),
+		Height:       2,
+		BlockHash:    latestBlock.Hash().String(),
+		StateRoot:    latestBlock.StateRoot.String(),
+		Block:        hex.EncodeToString(rawBlock),
+		Transactions: []string{hex.EncodeToString(rawTx)},
+		Receipts:     header.Receipts,
+	}, *header)
+	assert.Len(t, header.Receipts, 1)
+	_, err = app.ExportState(&bytes.Buffer{}, 3)
+	assert.Error(t, err)
+
+	t.Run("Should rebuild the same state root", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		header, err := target.app.ImportState(bytes.NewReader(exported.Bytes()))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), header.Height)
+
+		state := storage.NewStateStorage(target.app.State.Database)
+		assert.NoError(t, state.LoadState(latestBlock))
+		sender, _ := tr.getSenderWithNonce(0)
+		contract, err := state.GetAccount(crypto.NewDeploymentAddress(crypto.AddressFromPubKey(sender.PublicKey), 0))
+		assert.NoError(t, err)
+		assert.True(t, contract.IsContract())
+	})
+
+	t.Run("Should start app from imported state", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		_, err := target.app.ImportState(bytes.NewReader(exported.Bytes()))
+		assert.NoError(t, err)
+		target.app.Close()
+
+		app := NewApp(target.dbDir, db.DefaultBackend)
+		target.app = app
+		assert.Equal(t, types.ResponseInfo{LastBlockHeight: 2, LastBlockAppHash: appHash}, app.Info(types.RequestInfo{}))
+		assert.Equal(t, testChainID, app.Meta.ChainID())
+		assert.Equal(t, uint64(2), app.Meta.EarliestStateHeight())
+		txHeight, err := app.Meta.TxHashToBlockHeight(tr.getInvokeTx(1).Hash())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), txHeight)
+		_, _, err = app.loadStateAt(1)
+		assert.Equal(t, storage.ErrStatePruned, err)
+
+		// Next block is executed on imported state
+		app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{ChainID: testChainID, Height: 3, Time: time.Unix(3, 0), AppHash: appHash}})
+		rawTx, _ := tr.getInvokeTx(2).Encode()
+		assert.Equal(t, ResponseCodeOK, app.DeliverTx(types.RequestDeliverTx{Tx: rawTx}).Code)
+		app.Commit()
+		assert.Equal(t, uint64(3), app.Meta.LatestBlockHeight())
+
+		_, err = app.ImportState(bytes.NewReader(exported.Bytes()))
+		assert.EqualError(t, err, "State is only imported into chain without blocks")
+	})
+
+	t.Run("Should reject tampered state", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		tampered := strings.Replace(exported.String(), `"nonce":2`, `"nonce":3`, 1)
+		_, err := target.app.ImportState(strings.NewReader(tampered))
+		assert.Contains(t, err.Error(), "State root mismatch, expected "+latestBlock.StateRoot.String())
+		assert.False(t, target.app.State.Has(latestBlock.StateRoot.Bytes()))
+	})
+
+	t.Run("Should reject unknown version and chain ID", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		lines := strings.SplitN(exported.String(), "\n", 2)
+		_, err := target.app.ImportState(strings.NewReader(strings.Replace(lines[0], `"version":1`, `"version":9`, 1)))
+		assert.EqualError(t, err, "Unsupported state version 9")
+
+		target.app.Meta.StoreChainMetas(storage.ChainMetas{ChainID: "other-chain"})
+		_, err = target.app.ImportState(bytes.NewReader(exported.Bytes()))
+		assert.EqualError(t, err, "Chain ID mismatch, expected other-chain, got "+testChainID)
+		assert.False(t, target.app.State.Has(latestBlock.StateRoot.Bytes()))
+	})
+
+	t.Run("Should reject app state other than genesis", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		target.app.InitChain(types.RequestInitChain{ChainId: testChainID, AppStateBytes: []byte(`{"minGasPrice": 1}`)})
+		_, err := target.app.ImportState(bytes.NewReader(exported.Bytes()))
+		assert.EqualError(t, err, "App state mismatch, exported app state differs from genesis")
+		assert.False(t, target.app.State.Has(latestBlock.StateRoot.Bytes()))
+		assert.Equal(t, uint32(1), target.app.minGasPrice)
+	})
+}
+
+func TestApp_Snapshots(t *testing.T) {
+	tr := newAppTestResource()
+	defer tr.cleanData()
+	app := tr.app
+	app.snapshotChunkSize = 256
+	app.SetSnapshotOptions(SnapshotOptions{Interval: 2, KeepRecent: 1})
+
+	appHash := []byte{}
+	appHashes := [][]byte{}
+	txs := []*crypto.Transaction{tr.getDeployTx(0), tr.getInvokeTx(1), tr.getInvokeTx(2), tr.getInvokeTx(3)}
+	for i, tx := range txs {
+		app.BeginBlock(types.RequestBeginBlock{Header: tmproto.Header{Height: int64(i + 1), Time: time.Unix(int64(i+1), 0), AppHash: appHash}})
+		rawTx, _ := tx.Encode()
+		app.DeliverTx(types.RequestDeliverTx{Tx: rawTx})
+		appHash = app.Commit().Data
+		appHashes = append(appHashes, appHash)
+		// Snapshots are created in background, restarting snapshotter waits for the one at height 2
+		if i == 1 {
+			app.SetSnapshotOptions(app.snapshots)
+		}
+	}
+	app.snapshotter.stop()
+
+	// Only the latest snapshot is kept
+	snapshots := app.ListSnapshots(types.RequestListSnapshots{}).Snapshots
+	assert.Len(t, snapshots, 1)
+	snapshot := snapshots[0]
+	assert.Equal(t, uint64(4), snapshot.Height)
+	assert.Equal(t, snapshotFormat, snapshot.Format)
+	assert.True(t, snapshot.Chunks > 1)
+	chunks := [][]byte{}
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		res := app.LoadSnapshotChunk(types.RequestLoadSnapshotChunk{Height: snapshot.Height, Format: snapshot.Format, Chunk: i})
+		assert.NotEmpty(t, res.Chunk)
+		chunks = append(chunks, res.Chunk)
+	}
+	var exported bytes.Buffer
+	_, err := app.ExportState(&exported, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, exported.Bytes(), bytes.Join(chunks, nil))
+	assert.Empty(t, app.LoadSnapshotChunk(types.RequestLoadSnapshotChunk{Height: 2, Format: snapshot.Format}).Chunk)
+
+	offer := func(target *App, snapshot *types.Snapshot, appHash []byte) types.ResponseOfferSnapshot_Result {
+		return target.OfferSnapshot(types.RequestOfferSnapshot{Snapshot: snapshot, AppHash: appHash}).Result
+	}
+	apply := func(target *App, index int, chunk []byte) types.ResponseApplySnapshotChunk {
+		return target.ApplySnapshotChunk(types.RequestApplySnapshotChunk{Index: uint32(index), Chunk: chunk, Sender: "peer"})
+	}
+
+	t.Run("Should restore snapshot into chain without blocks", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		assert.Equal(t, types.ResponseOfferSnapshot_ACCEPT, offer(target.app, snapshot, appHashes[3]))
+		for i, chunk := range chunks {
+			assert.Equal(t, types.ResponseApplySnapshotChunk_ACCEPT, apply(target.app, i, chunk).Result)
+		}
+		assert.Equal(t, types.ResponseInfo{LastBlockHeight: 4, LastBlockAppHash: appHashes[3]}, target.app.Info(types.RequestInfo{}))
+		assert.Equal(t, testChainID, target.app.Meta.ChainID())
+		assert.Equal(t, types.ResponseOfferSnapshot_ABORT, offer(target.app, snapshot, appHashes[3]))
+	})
+
+	t.Run("Should refetch corrupted chunk", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		assert.Equal(t, types.ResponseOfferSnapshot_ACCEPT, offer(target.app, snapshot, appHashes[3]))
+		assert.Equal(t, types.ResponseApplySnapshotChunk{
+			Result:        types.ResponseApplySnapshotChunk_RETRY,
+			RefetchChunks: []uint32{0},
+			RejectSenders: []string{"peer"},
+		}, apply(target.app, 0, append([]byte{0}, chunks[0]...)))
+		assert.Equal(t, types.ResponseApplySnapshotChunk_ACCEPT, apply(target.app, 0, chunks[0]).Result)
+	})
+
+	t.Run("Should reject snapshot of untrusted app hash", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		assert.Equal(t, types.ResponseOfferSnapshot_ACCEPT, offer(target.app, snapshot, appHashes[2]))
+		result := types.ResponseApplySnapshotChunk_ACCEPT
+		for i, chunk := range chunks {
+			result = apply(target.app, i, chunk).Result
+		}
+		assert.Equal(t, types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT, result)
+		assert.Equal(t, uint64(0), target.app.Meta.LatestBlockHeight())
+	})
+
+	t.Run("Should reject unknown format and invalid metadata", func(t *testing.T) {
+		target := newAppTestResource()
+		defer target.cleanData()
+		unknown := *snapshot
+		unknown.Format = snapshotFormat + 1
+		assert.Equal(t, types.ResponseOfferSnapshot_REJECT_FORMAT, offer(target.app, &unknown, appHashes[3]))
+		invalid := *snapshot
+		invalid.Chunks++
+		assert.Equal(t, types.ResponseOfferSnapshot_REJECT, offer(target.app, &invalid, appHashes[3]))
+		assert.Equal(t, types.ResponseApplySnapshotChunk_ABORT, apply(target.app, 0, chunks[0]).Result)
+	})
+}
+
+func TestNewPruningOptions(t *testing.T) {
+	tests := []struct {
+		mode       string
+		keepRecent uint64
+		interval   uint64
+		want       PruningOptions
+		wantErr    bool
+	}{
+		{PruningArchive, 5, 0, PruningOptions{}, false},
+		{PruningEverything, 5, 0, PruningOptions{KeepRecent: 1, Interval: DefaultPruningInterval}, false},
+		{PruningRecent, 100, 20, PruningOptions{KeepRecent: 100, Interval: 20}, false},
+		{PruningRecent, 0, 20, PruningOptions{}, true},
+		{"unknown", 0, 0, PruningOptions{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := NewPruningOptions(tt.mode, tt.keepRecent, tt.interval)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPruningOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewPruningOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBlockHashAndAppHashConversion(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -351,7 +954,7 @@ func TestFullAppFlow(t *testing.T) {
 	appHash := []byte{}
 	for _, round := range rounds {
 		app.BeginBlock(types.RequestBeginBlock{
-			Header: types.Header{
+			Header: tmproto.Header{
 				Height:  round.height,
 				Time:    round.time,
 				AppHash: appHash,
@@ -381,3 +984,122 @@ func TestFullAppFlow(t *testing.T) {
 		}
 	}
 }
+
+func TestApp_Query(t *testing.T) {
+	tr := newAppTestResource()
+	defer tr.cleanData()
+	app := tr.app
+
+	appHash := []byte{}
+	for i, tx := range []*crypto.Transaction{tr.getDeployTx(0), tr.getInvokeTx(1)} {
+		app.BeginBlock(types.RequestBeginBlock{
+			Header: tmproto.Header{
+				Height:  int64(i + 1),
+				Time:    time.Unix(int64(i+1), 0),
+				AppHash: appHash,
+			},
+		})
+		rawTx, _ := tx.Encode()
+		app.DeliverTx(types.RequestDeliverTx{Tx: rawTx})
+		appHash = app.Commit().Data
+	}
+
+	sender, _ := tr.getSenderWithNonce(0)
+	senderAddress := crypto.AddressFromPubKey(sender.PublicKey)
+	contractAddress := crypto.NewDeploymentAddress(senderAddress, 0)
+	balance := make([]byte, 8)
+	binary.LittleEndian.PutUint64(balance, 1000)
+	block, _ := app.Chain.GetBlock(app.Meta.BlockHeightToBlockHash(2))
+
+	verifyProofOp := func(t *testing.T, root common.Hash, op tmCrypto.ProofOp) []byte {
+		var proof [][]byte
+		assert.NoError(t, rlp.DecodeBytes(op.Data, &proof))
+		value, err := trie.VerifyProof(root, op.Key, proof)
+		assert.NoError(t, err)
+		return value
+	}
+
+	t.Run("Query account", func(t *testing.T) {
+		res := app.Query(types.RequestQuery{Path: "/account/" + contractAddress.String(), Prove: true})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+		assert.Equal(t, int64(2), res.Height)
+		var account storage.Account
+		assert.NoError(t, rlp.DecodeBytes(res.Value, &account))
+		assert.Equal(t, senderAddress, account.Creator)
+		assert.Len(t, res.ProofOps.Ops, 1)
+		assert.Equal(t, ProofOpAccount, res.ProofOps.Ops[0].Type)
+		assert.Equal(t, res.Value, verifyProofOp(t, block.StateRoot, res.ProofOps.Ops[0]))
+	})
+
+	t.Run("Query storage", func(t *testing.T) {
+		path := fmt.Sprintf("/storage/%s/%x", contractAddress.String(), senderAddress[:])
+		res := app.Query(types.RequestQuery{Path: path, Prove: true})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+		assert.Equal(t, balance, res.Value)
+		assert.Len(t, res.ProofOps.Ops, 2)
+		assert.Equal(t, ProofOpStorage, res.ProofOps.Ops[0].Type)
+		assert.Equal(t, ProofOpAccount, res.ProofOps.Ops[1].Type)
+
+		var account storage.Account
+		assert.NoError(t, rlp.DecodeBytes(verifyProofOp(t, block.StateRoot, res.ProofOps.Ops[1]), &account))
+		assert.Equal(t, balance, verifyProofOp(t, account.StorageHash, res.ProofOps.Ops[0]))
+	})
+
+	t.Run("Query storage absence", func(t *testing.T) {
+		path := fmt.Sprintf("/storage/%s/%x", contractAddress.String(), contractAddress[:])
+		res := app.Query(types.RequestQuery{Path: path, Prove: true})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+		assert.Empty(t, res.Value)
+
+		var account storage.Account
+		assert.NoError(t, rlp.DecodeBytes(verifyProofOp(t, block.StateRoot, res.ProofOps.Ops[1]), &account))
+		assert.Nil(t, verifyProofOp(t, account.StorageHash, res.ProofOps.Ops[0]))
+	})
+
+	t.Run("Query storage at older height", func(t *testing.T) {
+		path := fmt.Sprintf("/storage/%s/%x", contractAddress.String(), senderAddress[:])
+		res := app.Query(types.RequestQuery{Path: path, Height: 1})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+		assert.Equal(t, int64(1), res.Height)
+		assert.Empty(t, res.Value)
+		assert.Nil(t, res.ProofOps)
+	})
+
+	t.Run("Query contract", func(t *testing.T) {
+		res := app.Query(types.RequestQuery{Path: "/contract/" + contractAddress.String()})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+		contract, err := abi.DecodeContract(res.Value)
+		assert.NoError(t, err)
+		_, err = contract.Header.GetFunction("mint")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Query transaction and receipt", func(t *testing.T) {
+		tx := tr.getInvokeTx(1)
+		res := app.Query(types.RequestQuery{Path: "/transaction/" + tx.Hash().String(), Prove: true})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+		assert.Equal(t, int64(2), res.Height)
+		assert.Equal(t, ProofOpTransaction, res.ProofOps.Ops[0].Type)
+		assert.Equal(t, res.Value, verifyProofOp(t, block.TransactionRoot, res.ProofOps.Ops[0]))
+		var decodedTx crypto.Transaction
+		assert.NoError(t, rlp.DecodeBytes(res.Value, &decodedTx))
+		assert.Equal(t, tx.Hash(), decodedTx.Hash())
+
+		res = app.Query(types.RequestQuery{Path: "/receipt/" + tx.Hash().String(), Prove: true})
+		assert.Equal(t, ResponseCodeOK, res.Code, res.Log)
+		assert.Equal(t, ProofOpReceipt, res.ProofOps.Ops[0].Type)
+		assert.Equal(t, res.Value, verifyProofOp(t, block.ReceiptRoot, res.ProofOps.Ops[0]))
+		var receipt crypto.Receipt
+		assert.NoError(t, rlp.DecodeBytes(res.Value, &receipt))
+		assert.Equal(t, tx.Hash(), receipt.Transaction)
+	})
+
+	t.Run("Query with invalid request", func(t *testing.T) {
+		res := app.Query(types.RequestQuery{Path: "/contract/" + senderAddress.String()})
+		assert.Equal(t, ResponseCodeNotOK, res.Code)
+		res = app.Query(types.RequestQuery{Path: "/unknown/" + senderAddress.String()})
+		assert.Equal(t, ResponseCodeNotOK, res.Code)
+		res = app.Query(types.RequestQuery{Path: "/account/" + senderAddress.String(), Height: 3})
+		assert.Equal(t, "Height 3 is greater than latest height 2", res.Log)
+	})
+}
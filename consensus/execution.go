@@ -6,19 +6,27 @@ import (
 	"github.com/QuoineFinancial/liquid-chain/abi"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
 	"github.com/QuoineFinancial/liquid-chain/engine"
+	"github.com/QuoineFinancial/liquid-chain/storage"
 )
 
 // InitFunctionName is default init function name
-const InitFunctionName = "init"
+const InitFunctionName = engine.InitFunctionName
+
+// MigrateFunctionName is function of upgraded contract run by upgrade transaction
+const MigrateFunctionName = "migrate"
 
 var (
-	initFunctionID = crypto.GetMethodID(InitFunctionName)
+	initFunctionID    = crypto.GetMethodID(InitFunctionName)
+	migrateFunctionID = crypto.GetMethodID(MigrateFunctionName)
 )
 
 func (app *App) applyTransaction(tx *crypto.Transaction) (*crypto.Receipt, error) {
 	if tx.Receiver == crypto.EmptyAddress {
 		return app.deployContract(tx)
 	}
+	if app.isContractUpgrade(tx) {
+		return app.upgradeContract(tx)
+	}
 	return app.invokeContract(tx)
 }
 
@@ -28,7 +36,7 @@ func (app *App) deployContract(tx *crypto.Transaction) (*crypto.Receipt, error)
 	}
 
 	contractSize := len(tx.Payload.Contract)
-	policy := app.gasStation.GetPolicy()
+	policy := app.gasPolicy()
 	receipt.GasUsed = uint32(policy.GetCostForContract(contractSize))
 	if tx.GasLimit < receipt.GasUsed {
 		receipt.Code = crypto.ReceiptCodeOutOfGas
@@ -42,7 +50,7 @@ func (app *App) deployContract(tx *crypto.Transaction) (*crypto.Receipt, error)
 
 	// Create contract account
 	senderAddress := crypto.AddressFromPubKey(tx.Sender.PublicKey)
-	contractAddress := crypto.NewDeploymentAddress(senderAddress, tx.Sender.Nonce)
+	contractAddress := tx.DeploymentAddress()
 	contractAccount, err := app.State.CreateAccount(senderAddress, contractAddress, tx.Payload.Contract)
 	if err != nil {
 		return nil, err
@@ -65,6 +73,7 @@ func (app *App) deployContract(tx *crypto.Transaction) (*crypto.Receipt, error)
 			app.State.Revert()
 		} else {
 			receipt.Result = result
+			receipt.SetReturnData(execEngine.GetReturnData())
 			receipt.Code = crypto.ReceiptCodeOK
 			receipt.Events = append(receipt.Events, execEngine.GetEvents()...)
 		}
@@ -106,7 +115,7 @@ func (app *App) invokeContract(tx *crypto.Transaction) (*crypto.Receipt, error)
 		return &receipt, nil
 	}
 
-	policy := app.gasStation.GetPolicy()
+	policy := app.gasPolicy()
 	senderAddress := crypto.AddressFromPubKey(tx.Sender.PublicKey)
 	execEngine := engine.NewEngine(app.State, contractAccount, senderAddress, policy, uint64(tx.GasLimit))
 
@@ -122,6 +131,7 @@ func (app *App) invokeContract(tx *crypto.Transaction) (*crypto.Receipt, error)
 		app.State.Revert()
 	} else {
 		receipt.Result = result
+		receipt.SetReturnData(execEngine.GetReturnData())
 		receipt.Events = append(receipt.Events, execEngine.GetEvents()...)
 	}
 
@@ -137,6 +147,102 @@ func (app *App) invokeContract(tx *crypto.Transaction) (*crypto.Receipt, error)
 	return &receipt, nil
 }
 
+// upgradeContract replaces contract of receiver by contract of payload, storage of receiver is kept.
+// Only creator or admin of receiver can upgrade it
+func (app *App) upgradeContract(tx *crypto.Transaction) (*crypto.Receipt, error) {
+	receipt := crypto.Receipt{
+		Transaction: tx.Hash(),
+	}
+	senderAddress := crypto.AddressFromPubKey(tx.Sender.PublicKey)
+	policy := app.gasPolicy()
+
+	contractAccount, err := app.State.LoadAccount(tx.Receiver)
+	if err != nil {
+		return nil, err
+	}
+	if contractAccount == nil || !contractAccount.IsContract() {
+		receipt.Code = crypto.ReceiptCodeContractNotFound
+	} else if !contractAccount.CanUpgrade(senderAddress) {
+		receipt.Code = crypto.ReceiptCodeUnauthorized
+	} else {
+		receipt.GasUsed = uint32(policy.GetCostForContract(len(tx.Payload.Contract)))
+		if tx.GasLimit < receipt.GasUsed {
+			receipt.Code = crypto.ReceiptCodeOutOfGas
+			receipt.GasUsed = tx.GasLimit
+		} else if err := app.applyUpgrade(tx, contractAccount, &receipt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := app.increaseNonce(senderAddress); err != nil {
+		return nil, err
+	}
+
+	gasEvents := app.gasStation.Burn(senderAddress, uint64(receipt.GasUsed)*uint64(tx.GasPrice))
+	receipt.Events = append(receipt.Events, gasEvents...)
+	receipt.PostState = app.State.Hash()
+	return &receipt, nil
+}
+
+// applyUpgrade sets contract of account and runs its migrate function if payload calls it
+func (app *App) applyUpgrade(tx *crypto.Transaction, contractAccount *storage.Account, receipt *crypto.Receipt) error {
+	contract, err := abi.DecodeContract(tx.Payload.Contract)
+	if err != nil {
+		return err
+	}
+	var migrateFunction *abi.Function
+	if bytes.Equal(tx.Payload.ID[:], migrateFunctionID[:]) {
+		if migrateFunction, err = contract.Header.GetFunctionByMethodID(tx.Payload.ID); err != nil {
+			return err
+		}
+	}
+
+	senderAddress := crypto.AddressFromPubKey(tx.Sender.PublicKey)
+	previousHash := contractAccount.ContractHash
+	contractAccount.SetContract(tx.Payload.Contract)
+
+	if migrateFunction != nil {
+		policy := app.gasPolicy()
+		execEngine := engine.NewEngine(app.State, contractAccount, senderAddress, policy, uint64(tx.GasLimit-receipt.GasUsed))
+		result, err := execEngine.Ignite(migrateFunction.Name, tx.Payload.Args)
+		receipt.GasUsed += uint32(execEngine.GetGasUsed())
+		if err != nil {
+			receipt.Code = crypto.ReceiptCodeIgniteError
+			app.State.Revert()
+			return nil
+		}
+		if !app.gasStation.Sufficient(senderAddress, uint64(receipt.GasUsed)*uint64(tx.GasPrice)) {
+			receipt.Code = crypto.ReceiptCodeOutOfGas
+			receipt.GasUsed = tx.GasLimit
+			app.State.Revert()
+			return nil
+		}
+		receipt.Result = result
+		receipt.SetReturnData(execEngine.GetReturnData())
+		receipt.Events = append(receipt.Events, execEngine.GetEvents()...)
+	}
+
+	upgradeArgs, err := abi.Encode(abi.UpgradeEvent.Parameters, []interface{}{
+		senderAddress,
+		previousHash.Bytes(),
+		contractAccount.ContractHash.Bytes(),
+	})
+	if err != nil {
+		return err
+	}
+	receipt.Code = crypto.ReceiptCodeOK
+	receipt.Events = append(receipt.Events, &crypto.Event{
+		ID:       abi.UpgradeEvent.ID(),
+		Args:     upgradeArgs,
+		Contract: contractAccount.GetAddress(),
+	})
+	app.contractUpgrades = append(app.contractUpgrades, storage.ContractUpgrade{
+		Contract:    contractAccount.GetAddress(),
+		Transaction: receipt.Transaction,
+	})
+	return nil
+}
+
 func (app *App) increaseNonce(address crypto.Address) error {
 	account, err := app.State.LoadAccount(address)
 	if err != nil {
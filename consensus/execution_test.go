@@ -6,16 +6,20 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"io/ioutil"
 	"math/rand"
 	"os"
-	"strconv"
 	"testing"
 
 	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
 	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/db"
 	"github.com/QuoineFinancial/liquid-chain/gas"
+	"github.com/QuoineFinancial/liquid-chain/storage"
 	"github.com/QuoineFinancial/liquid-chain/util"
+	"golang.org/x/crypto/blake2b"
 )
 
 type TestResource struct {
@@ -24,12 +28,11 @@ type TestResource struct {
 }
 
 func newTestResource() *TestResource {
-	dbDir := "./tmp" + strconv.Itoa(rand.Intn(10000))
-	err := os.MkdirAll(dbDir, os.ModePerm)
+	dbDir, err := ioutil.TempDir("", "liquid-consensus")
 	if err != nil {
 		panic(err)
 	}
-	app := NewApp(dbDir, "")
+	app := NewApp(dbDir, db.DefaultBackend)
 	if err := app.State.LoadState(&crypto.GenesisBlock); err != nil {
 		panic(err)
 	}
@@ -350,3 +353,151 @@ func TestApplyTx(t *testing.T) {
 		})
 	}
 }
+
+func TestUpgradeContract(t *testing.T) {
+	tr := newTestResource()
+	defer tr.cleanData()
+	tr.app.SetGasStation(gas.NewFreeStation(tr.app))
+
+	newSender := func(seed byte) *crypto.TxSender {
+		return &crypto.TxSender{PublicKey: ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, 32)).Public().(ed25519.PublicKey)}
+	}
+	creator, admin, stranger := newSender(1), newSender(2), newSender(3)
+	creatorAddress := crypto.AddressFromPubKey(creator.PublicKey)
+	adminAddress := crypto.AddressFromPubKey(admin.PublicKey)
+
+	deployPayload, err := util.BuildDeployTxPayload("../test/testdata/liquid-token.wasm", "../test/testdata/liquid-token-abi.json", "init", []string{"1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt, err := tr.app.applyTransaction(&crypto.Transaction{Sender: creator, Payload: deployPayload}); err != nil || receipt.Code != crypto.ReceiptCodeOK {
+		t.Fatalf("Expect contract deployed, got %v, %v", receipt, err)
+	}
+	creator.Nonce++
+	contractAddress := crypto.NewDeploymentAddress(creatorAddress, 0)
+	contractAccount, _ := tr.app.State.LoadAccount(contractAddress)
+	previousHash := contractAccount.ContractHash
+	storageHash := contractAccount.StorageHash
+	contractAccount.SetAdmin(adminAddress)
+
+	migratePayload, err := util.BuildDeployTxPayload("../test/testdata/migrate.wasm", "../test/testdata/migrate-abi.json", MigrateFunctionName, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgradeTx := func(sender *crypto.TxSender, payload *crypto.TxPayload) *crypto.Transaction {
+		return &crypto.Transaction{Sender: sender, Receiver: contractAddress, Payload: payload}
+	}
+
+	// Contract of payload is ignored before contract upgrade height
+	sameContractPayload := &crypto.TxPayload{Contract: deployPayload.Contract}
+	receipt, err := tr.app.applyTransaction(upgradeTx(creator, sameContractPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Code != crypto.ReceiptCodeMethodNotFound || contractAccount.ContractHash != previousHash {
+		t.Errorf("Expect tx invoking contract before upgrade height, got code %v", receipt.Code)
+	}
+	creator.Nonce++
+	tr.app.SetUpgradeHeights(UpgradeHeights{ContractUpgrade: 1})
+
+	receipt, err = tr.app.applyTransaction(upgradeTx(stranger, migratePayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Code != crypto.ReceiptCodeUnauthorized || contractAccount.ContractHash != previousHash {
+		t.Errorf("Expect unauthorized upgrade to be rejected, got code %v", receipt.Code)
+	}
+
+	missingMigratePayload := *migratePayload
+	missingMigratePayload.Contract = deployPayload.Contract
+	if _, err = tr.app.applyTransaction(upgradeTx(creator, &missingMigratePayload)); err == nil {
+		t.Errorf("Expect error for missing migrate function")
+	}
+	if contractAccount.ContractHash != previousHash {
+		t.Errorf("Expect contract not upgraded without migrate function")
+	}
+
+	receipt, err = tr.app.applyTransaction(upgradeTx(creator, sameContractPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Code != crypto.ReceiptCodeOK || contractAccount.ContractHash != previousHash {
+		t.Errorf("Expect upgrade by creator without migrate, got code %v", receipt.Code)
+	}
+
+	receipt, err = tr.app.applyTransaction(upgradeTx(admin, migratePayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Code != crypto.ReceiptCodeOK || receipt.Result != 2 {
+		t.Fatalf("Expect upgrade by admin with migrate, got code %v, result %v", receipt.Code, receipt.Result)
+	}
+	contractAccount, _ = tr.app.State.LoadAccount(contractAddress)
+	if contractAccount.ContractHash == previousHash || contractAccount.StorageHash == storageHash {
+		t.Errorf("Expect contract upgraded and migrated")
+	}
+	if version, _ := contractAccount.GetStorage([]byte("version")); !bytes.Equal(version, []byte("2")) {
+		t.Errorf("Expect migrated version 2, got %s", version)
+	}
+	if balance, _ := contractAccount.GetStorage(creatorAddress[:]); len(balance) == 0 {
+		t.Errorf("Expect storage kept after upgrade")
+	}
+
+	upgradeEvent := receipt.Events[len(receipt.Events)-1]
+	wantArgs, _ := abi.Encode(abi.UpgradeEvent.Parameters, []interface{}{adminAddress, previousHash.Bytes(), contractAccount.ContractHash.Bytes()})
+	if upgradeEvent.ID != abi.UpgradeEvent.ID() || upgradeEvent.Contract != contractAddress || !bytes.Equal(upgradeEvent.Args, wantArgs) {
+		t.Errorf("Expect upgrade event, got %v", upgradeEvent)
+	}
+
+	// Only upgrades applied by chain are indexed, rejected ones and invocations are not
+	if len(tr.app.contractUpgrades) != 2 || tr.app.contractUpgrades[1] != (storage.ContractUpgrade{Contract: contractAddress, Transaction: receipt.Transaction}) {
+		t.Errorf("Expect 2 upgrades recorded, got %v", tr.app.contractUpgrades)
+	}
+}
+
+func TestDeterministicDeployment(t *testing.T) {
+	tr := newTestResource()
+	defer tr.cleanData()
+	tr.app.SetGasStation(gas.NewFreeStation(tr.app))
+
+	privateKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, 32))
+	sender := &crypto.TxSender{PublicKey: privateKey.Public().(ed25519.PublicKey), Nonce: 5}
+	senderAddress := crypto.AddressFromPubKey(sender.PublicKey)
+	payload, err := util.BuildDeployTxPayload("../test/testdata/liquid-token.wasm", "../test/testdata/liquid-token-abi.json", "init", []string{"1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := common.BytesToHash([]byte("token"))
+	payload.SetSalt(salt)
+
+	tx := &crypto.Transaction{Sender: sender, Payload: payload}
+	want := crypto.NewDeterministicDeploymentAddress(senderAddress, salt, blake2b.Sum256(payload.Contract))
+	if address := tx.DeploymentAddress(); address != want {
+		t.Fatalf("Expect deployment address %v, got %v", want, address)
+	}
+	if receipt, err := tr.app.applyTransaction(tx); err != nil || receipt.Code != crypto.ReceiptCodeOK {
+		t.Fatalf("Expect contract deployed, got %v, %v", receipt, err)
+	}
+	contractAccount, _ := tr.app.State.LoadAccount(want)
+	if contractAccount == nil || !contractAccount.IsContract() {
+		t.Fatalf("Expect contract deployed at %v", want)
+	}
+	if legacy, _ := tr.app.State.LoadAccount(crypto.NewDeploymentAddress(senderAddress, sender.Nonce)); legacy != nil {
+		t.Errorf("Expect no contract at nonce deployment address")
+	}
+
+	senderAccount, _ := tr.app.State.LoadAccount(senderAddress)
+	redeployTx := &crypto.Transaction{Version: crypto.TxVersionLegacy, Sender: &crypto.TxSender{PublicKey: sender.PublicKey, Nonce: senderAccount.Nonce}, Payload: payload}
+	redeployTx.Signature = crypto.Sign(privateKey, crypto.GetSigHash(redeployTx, tr.app.Meta.ChainID()).Bytes())
+	if err := tr.app.validateTx(redeployTx); err == nil || err.Error() != "Contract address already exists" {
+		t.Errorf("Expect redeployment to the same address rejected, got %v", err)
+	}
+
+	saltsPayload := *payload
+	saltsPayload.Salt = append(saltsPayload.Salt, common.BytesToHash([]byte("other")))
+	saltsTx := &crypto.Transaction{Version: crypto.TxVersionLegacy, Sender: redeployTx.Sender, Payload: &saltsPayload}
+	saltsTx.Signature = crypto.Sign(privateKey, crypto.GetSigHash(saltsTx, tr.app.Meta.ChainID()).Bytes())
+	if err := tr.app.validateTx(saltsTx); err == nil || err.Error() != "Invalid salt. Expected at most 1, got 2" {
+		t.Errorf("Expect deployment with 2 salts rejected, got %v", err)
+	}
+}
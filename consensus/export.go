@@ -0,0 +1,185 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+)
+
+// ExportState writes state at height to writer, zero height exports the latest state
+func (app *App) ExportState(writer io.Writer, height int64) (*storage.StateExportHeader, error) {
+	stateHeight, state, err := app.loadStateAt(height)
+	if err != nil {
+		return nil, err
+	}
+	block := state.GetBlock()
+	header := storage.StateExportHeader{
+		Version:   storage.StateExportVersion,
+		ChainID:   app.Meta.ChainID(),
+		AppState:  app.Meta.GenesisAppState(),
+		Height:    stateHeight,
+		BlockHash: block.Hash().String(),
+		StateRoot: block.StateRoot.String(),
+	}
+	if err := app.exportBlock(&header, block); err != nil {
+		return nil, err
+	}
+	return &header, state.ExportState(writer, header)
+}
+
+func (app *App) exportBlock(header *storage.StateExportHeader, block *crypto.Block) error {
+	rawBlock, err := block.Encode()
+	if err != nil {
+		return err
+	}
+	header.Block = hex.EncodeToString(rawBlock)
+
+	txs, err := app.Chain.GetBlockTransactions(block)
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		rawTx, err := tx.Encode()
+		if err != nil {
+			return err
+		}
+		header.Transactions = append(header.Transactions, hex.EncodeToString(rawTx))
+	}
+
+	receipts, err := app.Chain.GetBlockReceipts(block)
+	if err != nil {
+		return err
+	}
+	for _, receipt := range receipts {
+		rawReceipt, err := receipt.Encode()
+		if err != nil {
+			return err
+		}
+		header.Receipts = append(header.Receipts, hex.EncodeToString(rawReceipt))
+	}
+	return nil
+}
+
+// ImportState rebuilds exported state into state database of a chain without blocks.
+// The exported block is stored as latest block, so app starts from its height.
+// Accounts are flushed in batches while importing, nodes of a rejected state are
+// unreachable since no block refers to them
+func (app *App) ImportState(reader io.Reader) (*storage.StateExportHeader, error) {
+	return app.importState(reader, nil)
+}
+
+// importState imports state with header checked by verify before anything refers to the state
+func (app *App) importState(reader io.Reader, verify func(*storage.StateExportHeader) error) (*storage.StateExportHeader, error) {
+	if app.Meta.LatestBlockHeight() != crypto.GenesisBlock.Height {
+		return nil, fmt.Errorf("State is only imported into chain without blocks")
+	}
+	state := storage.NewStateStorage(app.stateDB)
+	header, err := state.ImportState(reader, app.stateDB.Flush)
+	if err == nil && len(app.Meta.ChainID()) > 0 && header.ChainID != app.Meta.ChainID() {
+		err = fmt.Errorf("Chain ID mismatch, expected %s, got %s", app.Meta.ChainID(), header.ChainID)
+	}
+	if err == nil {
+		err = app.verifyAppState(header)
+	}
+	if err == nil && verify != nil {
+		err = verify(header)
+	}
+	if err == nil {
+		err = app.importBlock(header)
+	}
+	if err != nil {
+		app.stateDB.Discard()
+		app.chainDB.Discard()
+		return header, err
+	}
+	return header, nil
+}
+
+// verifyAppState rejects header whose app state differs from app state of genesis.
+// App state is not covered by app hash, so it is only taken from header of chain without genesis app state
+func (app *App) verifyAppState(header *storage.StateExportHeader) error {
+	if len(app.chainMetas.GenesisAppState) == 0 {
+		return nil
+	}
+	if len(header.AppState) > 0 {
+		appState, err := DecodeGenesisAppState(header.AppState)
+		if err != nil {
+			return err
+		}
+		rawAppState, err := appState.Encode()
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(rawAppState, app.chainMetas.GenesisAppState) {
+			return nil
+		}
+	}
+	return fmt.Errorf("App state mismatch, exported app state differs from genesis")
+}
+
+// importBlock stores block of imported state with chain metas of header as latest block
+func (app *App) importBlock(header *storage.StateExportHeader) error {
+	rawBlock, err := hex.DecodeString(header.Block)
+	if err != nil {
+		return fmt.Errorf("Invalid block: %v", err)
+	}
+	block, err := crypto.DecodeBlock(rawBlock)
+	if err != nil {
+		return fmt.Errorf("Invalid block: %v", err)
+	}
+	if block.Hash().String() != header.BlockHash || block.Height != header.Height || block.StateRoot.String() != header.StateRoot {
+		return fmt.Errorf("Block mismatch, expected %s at height %d", header.BlockHash, header.Height)
+	}
+
+	txs := []*crypto.Transaction{}
+	for _, encoded := range header.Transactions {
+		rawTx, err := hex.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("Invalid transaction: %v", err)
+		}
+		tx, err := crypto.DecodeTransaction(rawTx)
+		if err != nil {
+			return fmt.Errorf("Invalid transaction: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	receipts := []*crypto.Receipt{}
+	for _, encoded := range header.Receipts {
+		rawReceipt, err := hex.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("Invalid receipt: %v", err)
+		}
+		receipt, err := crypto.DecodeReceipt(rawReceipt)
+		if err != nil {
+			return fmt.Errorf("Invalid receipt: %v", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	if err := app.Chain.ImportBlock(block, txs, receipts); err != nil {
+		return err
+	}
+
+	// Chains initialized before app state was stored export no app state
+	appState, err := DecodeGenesisAppState(header.AppState)
+	if err != nil {
+		return err
+	}
+	app.chainMetas = storage.ChainMetas{ChainID: header.ChainID}
+	if len(header.AppState) > 0 {
+		if app.chainMetas.GenesisAppState, err = appState.Encode(); err != nil {
+			return err
+		}
+	}
+
+	if err := app.persist(block); err != nil {
+		return err
+	}
+	// States before the imported one do not exist
+	app.Meta.StoreEarliestStateHeight(block.Height)
+	app.loadGenesisAppState(appState)
+	return app.State.LoadState(block)
+}
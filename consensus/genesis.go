@@ -0,0 +1,192 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/gas"
+)
+
+// Gas stations which chain can start with
+const (
+	// GasStationFree charges no fee until the gas token is minted
+	GasStationFree = "free"
+
+	// GasStationLiquid charges fee in gas token from the first block
+	GasStationLiquid = "liquid"
+)
+
+// GenesisAppState is the app_state section of genesis.json
+type GenesisAppState struct {
+	GasContractAddress string             `json:"gasContractAddress"`
+	GasStation         string             `json:"gasStation"`
+	MinGasPrice        uint32             `json:"minGasPrice"`
+	Contracts          []*GenesisContract `json:"contracts,omitempty"`
+	Upgrades           UpgradeHeights     `json:"upgrades"`
+}
+
+// GenesisContract is a contract deployed into state at genesis.
+// Header has the format of contract header file, Code and Storage are hex encoded
+type GenesisContract struct {
+	Address string            `json:"address"`
+	Creator string            `json:"creator"`
+	Header  json.RawMessage   `json:"header"`
+	Code    string            `json:"code"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+// genesisAllocation is the decoded form of GenesisContract
+type genesisAllocation struct {
+	address  crypto.Address
+	creator  crypto.Address
+	contract []byte
+	storage  map[string][]byte
+}
+
+func (genesisContract *GenesisContract) decode() (*genesisAllocation, error) {
+	address, err := crypto.AddressFromString(genesisContract.Address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid contract address %s: %v", genesisContract.Address, err)
+	}
+	creator, err := crypto.AddressFromString(genesisContract.Creator)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid creator of contract %s: %v", genesisContract.Address, err)
+	}
+
+	encodedHeader, err := abi.EncodeHeaderJSONToBytes(genesisContract.Header)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid header of contract %s: %v", genesisContract.Address, err)
+	}
+	header, err := abi.DecodeHeader(encodedHeader)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid header of contract %s: %v", genesisContract.Address, err)
+	}
+	code, err := hex.DecodeString(genesisContract.Code)
+	if err != nil || len(code) == 0 {
+		return nil, fmt.Errorf("Invalid code of contract %s", genesisContract.Address)
+	}
+	contract, err := rlp.EncodeToBytes(&abi.Contract{Header: header, Code: code})
+	if err != nil {
+		return nil, err
+	}
+
+	storage := make(map[string][]byte)
+	for key, value := range genesisContract.Storage {
+		decodedKey, err := hex.DecodeString(key)
+		if err != nil || len(decodedKey) == 0 {
+			return nil, fmt.Errorf("Invalid storage key %s of contract %s", key, genesisContract.Address)
+		}
+		decodedValue, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid storage value of key %s of contract %s", key, genesisContract.Address)
+		}
+		storage[string(decodedKey)] = decodedValue
+	}
+
+	return &genesisAllocation{address, creator, contract, storage}, nil
+}
+
+// DecodeGenesisAppState parses and validates app_state, filling default values.
+// InitChain has no error response, so app_state is fully validated here before anything is stored
+func DecodeGenesisAppState(raw []byte) (*GenesisAppState, error) {
+	appState := GenesisAppState{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &appState); err != nil {
+			return nil, fmt.Errorf("Invalid app_state: %v", err)
+		}
+	}
+
+	if len(appState.GasStation) == 0 {
+		appState.GasStation = GasStationFree
+	}
+	if appState.MinGasPrice == 0 {
+		appState.MinGasPrice = gas.DefaultMinimumGasPrice
+	}
+
+	if len(appState.GasContractAddress) > 0 {
+		if _, err := crypto.AddressFromString(appState.GasContractAddress); err != nil {
+			return nil, fmt.Errorf("Invalid gas contract address: %v", err)
+		}
+	}
+
+	addresses := make(map[string]bool)
+	for _, contract := range appState.Contracts {
+		if _, err := contract.decode(); err != nil {
+			return nil, err
+		}
+		if addresses[contract.Address] {
+			return nil, fmt.Errorf("Duplicated contract address %s", contract.Address)
+		}
+		addresses[contract.Address] = true
+	}
+
+	switch appState.GasStation {
+	case GasStationFree:
+	case GasStationLiquid:
+		if len(appState.GasContractAddress) == 0 {
+			return nil, fmt.Errorf("Gas station %s requires gas contract address", appState.GasStation)
+		}
+		// Fee is charged from the first block, so gas contract must be deployed at genesis
+		if !addresses[appState.GasContractAddress] {
+			return nil, fmt.Errorf("Gas contract %s not found in genesis contracts", appState.GasContractAddress)
+		}
+	default:
+		return nil, fmt.Errorf("Unknown gas station %s", appState.GasStation)
+	}
+
+	return &appState, nil
+}
+
+// Encode returns bytes representation of app state
+func (appState GenesisAppState) Encode() ([]byte, error) {
+	return json.Marshal(appState)
+}
+
+// allocateGenesis deploys genesis contracts into state.
+// Genesis block with the pre-allocated state root is stored at genesis height, along with chain metas
+func (app *App) allocateGenesis(appState *GenesisAppState) error {
+	if err := app.State.LoadState(&crypto.GenesisBlock); err != nil {
+		return err
+	}
+	if len(appState.Contracts) == 0 {
+		return app.Meta.StoreChainMetas(app.chainMetas)
+	}
+
+	for _, genesisContract := range appState.Contracts {
+		allocation, err := genesisContract.decode()
+		if err != nil {
+			return err
+		}
+		account, err := app.State.CreateAccount(allocation.creator, allocation.address, allocation.contract)
+		if err != nil {
+			return err
+		}
+		for key, value := range allocation.storage {
+			if err := account.SetStorage([]byte(key), value); err != nil {
+				return err
+			}
+		}
+	}
+
+	genesisBlock := &crypto.Block{
+		Height:          crypto.GenesisBlock.Height,
+		Time:            crypto.GenesisBlock.Time,
+		Parent:          crypto.GenesisBlock.Parent,
+		StateRoot:       app.State.Commit(),
+		TransactionRoot: crypto.GenesisBlock.TransactionRoot,
+		ReceiptRoot:     crypto.GenesisBlock.ReceiptRoot,
+	}
+	rawBlock, err := genesisBlock.Encode()
+	if err != nil {
+		return err
+	}
+	app.Chain.Put(genesisBlock.Hash().Bytes(), rawBlock)
+	if err := app.persist(genesisBlock); err != nil {
+		return err
+	}
+	return app.State.LoadState(genesisBlock)
+}
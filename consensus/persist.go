@@ -0,0 +1,62 @@
+package consensus
+
+import (
+	"log"
+
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/trie"
+)
+
+// persist writes buffered state and chain data, then the metas of block and chain metas not stored yet.
+// State and chain are keyed by hash, so data flushed before a crash is unreachable until
+// metas are written. Metas are written last in one batch, which makes the block commit atomic
+func (app *App) persist(block *crypto.Block) error {
+	app.persistMutex.Lock()
+	defer app.persistMutex.Unlock()
+	if err := app.stateDB.Flush(); err != nil {
+		return err
+	}
+	if err := app.chainDB.Flush(); err != nil {
+		return err
+	}
+	return app.Meta.StoreBlockMetas(block, app.chainMetas, app.contractUpgrades)
+}
+
+// recover rolls latest block height back to the highest block whose data is fully stored.
+// Tendermint replays the blocks after it on handshake. Chain metas are written with block metas,
+// so a chain whose genesis was not persisted has no chain metas and is initialized again
+func (app *App) recover() error {
+	latestHeight := app.Meta.LatestBlockHeight()
+	height := latestHeight
+	for height > crypto.GenesisBlock.Height && !app.isBlockStored(height) {
+		height--
+	}
+	if height != latestHeight {
+		log.Printf("Recover latest block height from %d to %d", latestHeight, height)
+		return app.Meta.RollbackLatestBlockHeight(height)
+	}
+	return nil
+}
+
+// isBlockStored checks that block at height, its state root and chain roots are in storage
+func (app *App) isBlockStored(height uint64) bool {
+	blockHash := app.Meta.BlockHeightToBlockHash(height)
+	if blockHash == common.EmptyHash || !app.Chain.Has(blockHash.Bytes()) {
+		return false
+	}
+	block, err := app.Chain.GetBlock(blockHash)
+	if err != nil || block.Hash() != blockHash {
+		return false
+	}
+	if _, err := trie.New(block.StateRoot, app.State.Database); err != nil {
+		return false
+	}
+	if _, err := trie.New(block.TransactionRoot, app.Chain.Database); err != nil {
+		return false
+	}
+	if _, err := trie.New(block.ReceiptRoot, app.Chain.Database); err != nil {
+		return false
+	}
+	return true
+}
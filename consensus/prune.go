@@ -0,0 +1,125 @@
+package consensus
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+)
+
+// Pruning modes of historical states
+const (
+	// PruningArchive keeps states of all heights
+	PruningArchive = "archive"
+
+	// PruningRecent keeps states of the last KeepRecent heights
+	PruningRecent = "recent"
+
+	// PruningEverything keeps state of the latest height only
+	PruningEverything = "everything"
+)
+
+// DefaultPruningInterval is number of heights between two prunings.
+// Each pruning marks all kept states and scans the whole state database, so it runs rarely
+const DefaultPruningInterval = uint64(1000)
+
+// PruningOptions decides which historical states are kept in state database
+type PruningOptions struct {
+	// KeepRecent is number of latest heights whose states are kept, 0 keeps all states
+	KeepRecent uint64
+
+	// Interval is number of heights between two prunings
+	Interval uint64
+}
+
+// NewPruningOptions returns pruning options of mode.
+// keepRecent is only used by recent mode, zero interval falls back to DefaultPruningInterval
+func NewPruningOptions(mode string, keepRecent uint64, interval uint64) (PruningOptions, error) {
+	if interval == 0 {
+		interval = DefaultPruningInterval
+	}
+	switch mode {
+	case PruningArchive:
+		return PruningOptions{}, nil
+	case PruningEverything:
+		return PruningOptions{KeepRecent: 1, Interval: interval}, nil
+	case PruningRecent:
+		if keepRecent == 0 {
+			return PruningOptions{}, fmt.Errorf("Pruning mode %s requires positive number of kept heights", mode)
+		}
+		return PruningOptions{KeepRecent: keepRecent, Interval: interval}, nil
+	default:
+		return PruningOptions{}, fmt.Errorf("Unsupported pruning mode %s", mode)
+	}
+}
+
+// SetPruningOptions sets which historical states are kept.
+// States are pruned in background, so commits never wait for pruning
+func (app *App) SetPruningOptions(options PruningOptions) {
+	app.pruner.stop()
+	app.pruning = options
+	app.pruner = nil
+	if options.KeepRecent > 0 && options.Interval > 0 {
+		app.pruner = newHeightWorker("prune states", app.pruneStates)
+	}
+}
+
+// schedulePruning raises watermark of pruner every pruning interval
+func (app *App) schedulePruning(height uint64) {
+	if app.pruner != nil && height%app.pruning.Interval == 0 {
+		app.pruner.raise(height)
+	}
+}
+
+// pruneStates removes states older than the kept heights before height.
+// Blocks persisted while unreachable keys are collected are retained before sweeping, holding off
+// persist so no node written again by them is removed. Earliest state height is stored before removing,
+// so queries on removed states fail with ErrStatePruned
+func (app *App) pruneStates(height uint64) error {
+	if app.pruning.KeepRecent == 0 || height < app.pruning.KeepRecent {
+		return nil
+	}
+	earliestHeight := height - app.pruning.KeepRecent + 1
+	if earliestHeight <= app.Meta.EarliestStateHeight() {
+		return nil
+	}
+
+	pruning := storage.NewStatePruning(app.stateStore)
+	if err := app.retainStates(pruning, earliestHeight, height); err != nil {
+		return err
+	}
+	pruning.Collect()
+
+	app.persistMutex.Lock()
+	defer app.persistMutex.Unlock()
+	if err := app.retainStates(pruning, height+1, app.Meta.LatestBlockHeight()); err != nil {
+		return err
+	}
+	// State being written to a snapshot is kept until the snapshot is created
+	if pinned := app.snapshotHeight; pinned > 0 && pinned < earliestHeight {
+		if err := app.retainStates(pruning, pinned, pinned); err != nil {
+			return err
+		}
+	}
+	app.Meta.StoreEarliestStateHeight(earliestHeight)
+	removed, err := pruning.Sweep()
+	if err != nil {
+		return err
+	}
+	log.Printf("Pruned %d state entries before height %d", removed, earliestHeight)
+	return nil
+}
+
+// retainStates retains states of blocks from height start to end
+func (app *App) retainStates(pruning *storage.StatePruning, start uint64, end uint64) error {
+	roots := []common.Hash{}
+	for h := start; h <= end; h++ {
+		block, err := app.loadBlockAt(h)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, block.StateRoot)
+	}
+	return pruning.Retain(roots)
+}
@@ -0,0 +1,227 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+	"github.com/QuoineFinancial/liquid-chain/trie"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmCrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+)
+
+// Supported paths of ABCI query
+const (
+	QueryPathAccount     = "account"
+	QueryPathStorage     = "storage"
+	QueryPathContract    = "contract"
+	QueryPathTransaction = "transaction"
+	QueryPathReceipt     = "receipt"
+)
+
+// Types of proof operations returned by ABCI query
+const (
+	// ProofOpAccount proves an encoded account under the StateRoot of block
+	ProofOpAccount = "liquid:account"
+
+	// ProofOpStorage proves a storage value under the StorageHash of account
+	ProofOpStorage = "liquid:storage"
+
+	// ProofOpTransaction proves an encoded transaction under the TransactionRoot of block
+	ProofOpTransaction = "liquid:transaction"
+
+	// ProofOpReceipt proves an encoded receipt under the ReceiptRoot of block
+	ProofOpReceipt = "liquid:receipt"
+)
+
+// Query reads account, storage or contract at requested height.
+// Supported paths are /account/<address>, /storage/<address>/<hex key> and /contract/<address>.
+// Committed transactions and receipts are read by /transaction/<hex tx hash> and /receipt/<hex tx hash>.
+// Merkle proofs from the state, transaction or receipt trie are returned when req.Prove is true
+func (app *App) Query(req abciTypes.RequestQuery) abciTypes.ResponseQuery {
+	path := strings.Split(strings.Trim(req.Path, "/"), "/")
+	if len(path) == 2 && (path[0] == QueryPathTransaction || path[0] == QueryPathReceipt) {
+		res, err := app.queryChain(path, req.Prove)
+		if err != nil {
+			return abciTypes.ResponseQuery{Code: ResponseCodeNotOK, Log: err.Error()}
+		}
+		return *res
+	}
+
+	height, state, err := app.loadStateAt(req.Height)
+	if err != nil {
+		return abciTypes.ResponseQuery{Code: ResponseCodeNotOK, Log: err.Error()}
+	}
+
+	res, err := app.queryState(state, path, req.Prove)
+	if err != nil {
+		return abciTypes.ResponseQuery{Code: ResponseCodeNotOK, Log: err.Error(), Height: int64(height)}
+	}
+	res.Height = int64(height)
+	return *res
+}
+
+func (app *App) loadBlockAt(height uint64) (*crypto.Block, error) {
+	blockHash := app.Meta.BlockHeightToBlockHash(height)
+	if blockHash == common.EmptyHash && height != crypto.GenesisBlock.Height {
+		return nil, fmt.Errorf("Block %d not found", height)
+	}
+	return app.Chain.GetBlock(blockHash)
+}
+
+func (app *App) loadStateAt(requestHeight int64) (uint64, *storage.StateStorage, error) {
+	if requestHeight < 0 {
+		return 0, nil, fmt.Errorf("Invalid height %d", requestHeight)
+	}
+
+	height := uint64(requestHeight)
+	latestHeight := app.Meta.LatestBlockHeight()
+	if height == 0 {
+		height = latestHeight
+	}
+	if height > latestHeight {
+		return 0, nil, fmt.Errorf("Height %d is greater than latest height %d", height, latestHeight)
+	}
+	if err := app.Meta.CheckStateHeight(height); err != nil {
+		return 0, nil, err
+	}
+
+	block, err := app.loadBlockAt(height)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	state := storage.NewStateStorage(app.State.Database)
+	if err := state.LoadState(block); err != nil {
+		return 0, nil, err
+	}
+	return height, state, nil
+}
+
+func (app *App) queryState(state *storage.StateStorage, path []string, prove bool) (*abciTypes.ResponseQuery, error) {
+	if len(path) < 2 {
+		return nil, fmt.Errorf("Invalid query path /%s", strings.Join(path, "/"))
+	}
+
+	address, err := crypto.AddressFromString(path[1])
+	if err != nil {
+		return nil, err
+	}
+	account, err := state.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	res := abciTypes.ResponseQuery{Code: ResponseCodeOK, Key: address[:]}
+	switch {
+	case path[0] == QueryPathAccount && len(path) == 2:
+		if account != nil {
+			if res.Value, err = rlp.EncodeToBytes(account); err != nil {
+				return nil, err
+			}
+		}
+
+	case path[0] == QueryPathContract && len(path) == 2:
+		if account == nil || !account.IsContract() {
+			return nil, fmt.Errorf("Contract %s not found", path[1])
+		}
+		res.Value = account.GetContractBytes()
+
+	case path[0] == QueryPathStorage && len(path) == 3:
+		key, err := hex.DecodeString(path[2])
+		if err != nil {
+			return nil, err
+		}
+		res.Key = key
+		if account != nil {
+			if res.Value, err = account.GetStorage(key); err != nil {
+				return nil, err
+			}
+		}
+		if prove {
+			res.ProofOps = &tmCrypto.ProofOps{}
+			if account != nil {
+				storageProof, err := account.ProveStorage(key)
+				if err != nil {
+					return nil, err
+				}
+				if err := appendProofOp(res.ProofOps, ProofOpStorage, key, storageProof); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("Invalid query path /%s", strings.Join(path, "/"))
+	}
+
+	if prove {
+		if res.ProofOps == nil {
+			res.ProofOps = &tmCrypto.ProofOps{}
+		}
+		accountProof, err := state.ProveAccount(address)
+		if err != nil {
+			return nil, err
+		}
+		if err := appendProofOp(res.ProofOps, ProofOpAccount, address[:], accountProof); err != nil {
+			return nil, err
+		}
+	}
+	return &res, nil
+}
+
+func (app *App) queryChain(path []string, prove bool) (*abciTypes.ResponseQuery, error) {
+	if _, err := hex.DecodeString(path[1]); err != nil {
+		return nil, err
+	}
+	txHash := common.HexToHash(path[1])
+	height, err := app.Meta.TxHashToBlockHeight(txHash)
+	if err != nil {
+		return nil, err
+	}
+	block, err := app.loadBlockAt(height)
+	if err != nil {
+		return nil, err
+	}
+
+	root, key, opType := block.TransactionRoot, txHash, ProofOpTransaction
+	if path[0] == QueryPathReceipt {
+		root, key, opType = block.ReceiptRoot, app.Meta.TxHashToReceiptHash(txHash), ProofOpReceipt
+	}
+
+	chainTrie, err := trie.New(root, app.Chain.Database)
+	if err != nil {
+		return nil, err
+	}
+	value, err := chainTrie.Get(key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	res := abciTypes.ResponseQuery{Code: ResponseCodeOK, Key: key.Bytes(), Value: value, Height: int64(height)}
+	if prove {
+		proof, err := chainTrie.Prove(key.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		res.ProofOps = &tmCrypto.ProofOps{}
+		if err := appendProofOp(res.ProofOps, opType, key.Bytes(), proof); err != nil {
+			return nil, err
+		}
+	}
+	return &res, nil
+}
+
+func appendProofOp(proof *tmCrypto.ProofOps, opType string, key []byte, nodes [][]byte) error {
+	data, err := rlp.EncodeToBytes(nodes)
+	if err != nil {
+		return err
+	}
+	proof.Ops = append(proof.Ops, tmCrypto.ProofOp{Type: opType, Key: key, Data: data})
+	return nil
+}
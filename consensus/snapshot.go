@@ -0,0 +1,344 @@
+package consensus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+const (
+	snapshotsDir = "snapshots"
+
+	// snapshotFile describes a snapshot in its directory, next to the chunks named by their index
+	snapshotFile = "snapshot.json"
+
+	// restoreFile collects chunks of the snapshot being restored
+	restoreFile = "restore"
+
+	// defaultSnapshotChunkSize is size of a snapshot chunk, Tendermint limits chunk size to 16MB
+	defaultSnapshotChunkSize = 10 << 20
+)
+
+// snapshotFormat is format of snapshot chunks, which split a state export file
+const snapshotFormat = uint32(storage.StateExportVersion)
+
+// SnapshotOptions decides at which heights state snapshots are created for state sync
+type SnapshotOptions struct {
+	// Interval is number of heights between two snapshots, 0 disables snapshots
+	Interval uint64
+
+	// KeepRecent is number of latest snapshots kept, 0 keeps all snapshots
+	KeepRecent uint64
+}
+
+// SetSnapshotOptions sets at which heights snapshots are created.
+// Snapshots are created in background, so commits never wait for them
+func (app *App) SetSnapshotOptions(options SnapshotOptions) {
+	app.snapshotter.stop()
+	app.snapshots = options
+	app.snapshotter = nil
+	if options.Interval > 0 {
+		app.snapshotter = newHeightWorker("create snapshot", app.createSnapshot)
+	}
+}
+
+// scheduleSnapshot raises watermark of snapshotter every snapshot interval
+func (app *App) scheduleSnapshot(height uint64) {
+	if app.snapshotter != nil && height%app.snapshots.Interval == 0 {
+		app.snapshotter.raise(height)
+	}
+}
+
+// createSnapshot writes state export at height as chunks of a snapshot.
+// Chunks are hashed into metadata so restoring node verifies each chunk, snapshot hash is hash of metadata
+func (app *App) createSnapshot(height uint64) error {
+	// State is pinned against pruning unless it is pruned already
+	app.persistMutex.Lock()
+	err := app.Meta.CheckStateHeight(height)
+	if err == nil {
+		app.snapshotHeight = height
+	}
+	app.persistMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		app.persistMutex.Lock()
+		app.snapshotHeight = 0
+		app.persistMutex.Unlock()
+	}()
+
+	// Snapshot is written into a temporary directory, then renamed so it is listed only once complete
+	dir := app.snapshotPath(height)
+	tmpDir := dir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmpDir, os.ModePerm); err != nil {
+		return err
+	}
+	writer := &chunkWriter{dir: tmpDir, size: app.snapshotChunkSize}
+	if _, err := app.ExportState(writer, int64(height)); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+
+	metadata := bytes.Join(writer.hashes, nil)
+	hash := sha256.Sum256(metadata)
+	snapshot := abciTypes.Snapshot{
+		Height:   height,
+		Format:   snapshotFormat,
+		Chunks:   uint32(len(writer.hashes)),
+		Hash:     hash[:],
+		Metadata: metadata,
+	}
+	rawSnapshot, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, snapshotFile), rawSnapshot, 0644); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return err
+	}
+	log.Printf("Created snapshot at height %d with %d chunks", height, snapshot.Chunks)
+	return app.pruneSnapshots()
+}
+
+// pruneSnapshots removes snapshots older than the kept ones
+func (app *App) pruneSnapshots() error {
+	if app.snapshots.KeepRecent == 0 {
+		return nil
+	}
+	snapshots, err := app.listSnapshots()
+	if err != nil {
+		return err
+	}
+	for i := int(app.snapshots.KeepRecent); i < len(snapshots); i++ {
+		if err := os.RemoveAll(app.snapshotPath(snapshots[i].Height)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (app *App) snapshotPath(height uint64) string {
+	return filepath.Join(app.snapshotDir, strconv.FormatUint(height, 10))
+}
+
+// listSnapshots returns complete snapshots, latest first
+func (app *App) listSnapshots() ([]*abciTypes.Snapshot, error) {
+	entries, err := ioutil.ReadDir(app.snapshotDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	snapshots := []*abciTypes.Snapshot{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+		rawSnapshot, err := ioutil.ReadFile(filepath.Join(app.snapshotDir, entry.Name(), snapshotFile))
+		if err != nil {
+			return nil, err
+		}
+		var snapshot abciTypes.Snapshot
+		if err := json.Unmarshal(rawSnapshot, &snapshot); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Height > snapshots[j].Height })
+	return snapshots, nil
+}
+
+// ListSnapshots returns snapshots available for state sync of other nodes
+func (app *App) ListSnapshots(req abciTypes.RequestListSnapshots) abciTypes.ResponseListSnapshots {
+	snapshots, err := app.listSnapshots()
+	if err != nil {
+		log.Printf("Failed to list snapshots: %v", err)
+	}
+	return abciTypes.ResponseListSnapshots{Snapshots: snapshots}
+}
+
+// LoadSnapshotChunk returns a chunk of snapshot, empty if the snapshot does not exist
+func (app *App) LoadSnapshotChunk(req abciTypes.RequestLoadSnapshotChunk) abciTypes.ResponseLoadSnapshotChunk {
+	if req.Format != snapshotFormat {
+		return abciTypes.ResponseLoadSnapshotChunk{}
+	}
+	chunk, err := ioutil.ReadFile(filepath.Join(app.snapshotPath(req.Height), strconv.FormatUint(uint64(req.Chunk), 10)))
+	if err != nil {
+		log.Printf("Failed to load chunk %d of snapshot at height %d: %v", req.Chunk, req.Height, err)
+		return abciTypes.ResponseLoadSnapshotChunk{}
+	}
+	return abciTypes.ResponseLoadSnapshotChunk{Chunk: chunk}
+}
+
+// snapshotRestore is a snapshot accepted for state sync, its chunks are collected into a file
+type snapshotRestore struct {
+	snapshot *abciTypes.Snapshot
+	appHash  []byte
+	file     *os.File
+	next     uint32
+}
+
+func (restore *snapshotRestore) chunkHash(index uint32) []byte {
+	return restore.snapshot.Metadata[index*sha256.Size : (index+1)*sha256.Size]
+}
+
+func (restore *snapshotRestore) close() {
+	restore.file.Close()
+	os.Remove(restore.file.Name())
+}
+
+// OfferSnapshot accepts a snapshot to restore state of a chain without blocks
+func (app *App) OfferSnapshot(req abciTypes.RequestOfferSnapshot) abciTypes.ResponseOfferSnapshot {
+	snapshot := req.Snapshot
+	switch {
+	case snapshot == nil:
+		return abciTypes.ResponseOfferSnapshot{Result: abciTypes.ResponseOfferSnapshot_REJECT}
+	case snapshot.Format != snapshotFormat:
+		return abciTypes.ResponseOfferSnapshot{Result: abciTypes.ResponseOfferSnapshot_REJECT_FORMAT}
+	case app.Meta.LatestBlockHeight() != crypto.GenesisBlock.Height:
+		return abciTypes.ResponseOfferSnapshot{Result: abciTypes.ResponseOfferSnapshot_ABORT}
+	}
+	hash := sha256.Sum256(snapshot.Metadata)
+	if snapshot.Chunks == 0 || len(snapshot.Metadata) != int(snapshot.Chunks)*sha256.Size || !bytes.Equal(hash[:], snapshot.Hash) {
+		return abciTypes.ResponseOfferSnapshot{Result: abciTypes.ResponseOfferSnapshot_REJECT}
+	}
+
+	if app.restore != nil {
+		app.restore.close()
+		app.restore = nil
+	}
+	if err := os.MkdirAll(app.snapshotDir, os.ModePerm); err != nil {
+		log.Printf("Failed to restore snapshot: %v", err)
+		return abciTypes.ResponseOfferSnapshot{Result: abciTypes.ResponseOfferSnapshot_ABORT}
+	}
+	file, err := os.Create(filepath.Join(app.snapshotDir, restoreFile))
+	if err != nil {
+		log.Printf("Failed to restore snapshot: %v", err)
+		return abciTypes.ResponseOfferSnapshot{Result: abciTypes.ResponseOfferSnapshot_ABORT}
+	}
+	app.restore = &snapshotRestore{snapshot: snapshot, appHash: req.AppHash, file: file}
+	return abciTypes.ResponseOfferSnapshot{Result: abciTypes.ResponseOfferSnapshot_ACCEPT}
+}
+
+// ApplySnapshotChunk verifies chunk against snapshot metadata and collects it.
+// State is imported once all chunks are collected, its block must have the trusted app hash
+func (app *App) ApplySnapshotChunk(req abciTypes.RequestApplySnapshotChunk) abciTypes.ResponseApplySnapshotChunk {
+	restore := app.restore
+	if restore == nil {
+		return abciTypes.ResponseApplySnapshotChunk{Result: abciTypes.ResponseApplySnapshotChunk_ABORT}
+	}
+	if req.Index != restore.next {
+		return abciTypes.ResponseApplySnapshotChunk{Result: abciTypes.ResponseApplySnapshotChunk_RETRY_SNAPSHOT}
+	}
+	if hash := sha256.Sum256(req.Chunk); !bytes.Equal(hash[:], restore.chunkHash(req.Index)) {
+		return abciTypes.ResponseApplySnapshotChunk{
+			Result:        abciTypes.ResponseApplySnapshotChunk_RETRY,
+			RefetchChunks: []uint32{req.Index},
+			RejectSenders: []string{req.Sender},
+		}
+	}
+	if _, err := restore.file.Write(req.Chunk); err != nil {
+		log.Printf("Failed to restore snapshot: %v", err)
+		return abciTypes.ResponseApplySnapshotChunk{Result: abciTypes.ResponseApplySnapshotChunk_ABORT}
+	}
+	restore.next++
+	if restore.next < restore.snapshot.Chunks {
+		return abciTypes.ResponseApplySnapshotChunk{Result: abciTypes.ResponseApplySnapshotChunk_ACCEPT}
+	}
+
+	app.restore = nil
+	defer restore.close()
+	if err := app.restoreSnapshot(restore); err != nil {
+		log.Printf("Failed to restore snapshot at height %d: %v", restore.snapshot.Height, err)
+		return abciTypes.ResponseApplySnapshotChunk{Result: abciTypes.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}
+	}
+	return abciTypes.ResponseApplySnapshotChunk{Result: abciTypes.ResponseApplySnapshotChunk_ACCEPT}
+}
+
+func (app *App) restoreSnapshot(restore *snapshotRestore) error {
+	if _, err := restore.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := app.importState(restore.file, func(header *storage.StateExportHeader) error {
+		blockHash := appHashToBlockHash(restore.appHash)
+		if header.Height != restore.snapshot.Height || header.BlockHash != blockHash.String() {
+			return fmt.Errorf("Block mismatch, expected %s at height %d", blockHash, restore.snapshot.Height)
+		}
+		return nil
+	})
+	return err
+}
+
+// chunkWriter splits written bytes into chunk files of size bytes named by their index
+type chunkWriter struct {
+	dir    string
+	size   int
+	chunk  bytes.Buffer
+	hashes [][]byte
+}
+
+func (writer *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := writer.size - writer.chunk.Len()
+		if n > len(p) {
+			n = len(p)
+		}
+		writer.chunk.Write(p[:n])
+		p = p[n:]
+		written += n
+		if writer.chunk.Len() == writer.size {
+			if err := writer.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close writes the last chunk
+func (writer *chunkWriter) Close() error {
+	if writer.chunk.Len() == 0 {
+		return nil
+	}
+	return writer.flush()
+}
+
+func (writer *chunkWriter) flush() error {
+	path := filepath.Join(writer.dir, strconv.Itoa(len(writer.hashes)))
+	if err := ioutil.WriteFile(path, writer.chunk.Bytes(), 0644); err != nil {
+		return err
+	}
+	hash := sha256.Sum256(writer.chunk.Bytes())
+	writer.hashes = append(writer.hashes, hash[:])
+	writer.chunk.Reset()
+	return nil
+}
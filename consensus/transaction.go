@@ -8,9 +8,13 @@ import (
 )
 
 func (app *App) validateTx(tx *crypto.Transaction) error {
-	if tx.Version != 1 {
+	// TxVersionLegacy is accepted until the legacy tx cutoff height while clients migrate to TxVersionChainID
+	if tx.Version != crypto.TxVersionLegacy && tx.Version != crypto.TxVersionChainID {
 		return fmt.Errorf("tx version %d not supported", tx.Version)
 	}
+	if tx.Version == crypto.TxVersionLegacy && isActivated(app.upgrades.LegacyTxCutoff, app.executingHeight()) {
+		return fmt.Errorf("tx version %d not supported since height %d", tx.Version, app.upgrades.LegacyTxCutoff)
+	}
 
 	nonce := uint64(0)
 	address := crypto.AddressFromPubKey(tx.Sender.PublicKey)
@@ -28,14 +32,45 @@ func (app *App) validateTx(tx *crypto.Transaction) error {
 	}
 
 	// Validate tx signature
-	signingHash := crypto.GetSigHash(tx)
+	chainID := app.chainMetas.ChainID
+	if tx.Version == crypto.TxVersionChainID && len(chainID) == 0 {
+		return fmt.Errorf("Chain ID is not initialized")
+	}
+	signingHash := crypto.GetSigHash(tx, chainID)
 	if valid := crypto.VerifySignature(tx.Sender.PublicKey, signingHash.Bytes(), tx.Signature); !valid {
 		return fmt.Errorf("Invalid signature")
 	}
 
+	if len(tx.Payload.Salt) > 1 {
+		return fmt.Errorf("Invalid salt. Expected at most 1, got %v", len(tx.Payload.Salt))
+	}
+	if _, salted := tx.Payload.GetSalt(); salted && tx.Receiver == crypto.EmptyAddress {
+		existing, err := app.State.LoadAccount(tx.DeploymentAddress())
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("Contract address already exists")
+		}
+	}
+
+	// Sender is authorized by execution, which records unauthorized upgrade in receipt
+	if app.isContractUpgrade(tx) {
+		account, err := app.State.LoadAccount(tx.Receiver)
+		if err != nil {
+			return err
+		}
+		if account == nil || !account.IsContract() {
+			return fmt.Errorf("Upgrade a non-contract account")
+		}
+		if _, err := abi.DecodeContract(tx.Payload.Contract); err != nil {
+			return err
+		}
+	}
+
 	if tx.Payload.ID != (crypto.MethodID{}) {
 		var contract *abi.Contract
-		if tx.Receiver != crypto.EmptyAddress {
+		if tx.Receiver != crypto.EmptyAddress && !app.isContractUpgrade(tx) {
 			account, err := app.State.LoadAccount(tx.Receiver)
 			if err != nil {
 				return err
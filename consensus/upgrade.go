@@ -0,0 +1,66 @@
+package consensus
+
+import (
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/gas"
+)
+
+// UpgradeHeights are block heights from which consensus changes take effect.
+// Zero height never activates the change, so chains replay their blocks under the old rules
+type UpgradeHeights struct {
+	// LegacyTxCutoff rejects TxVersionLegacy, which is signed without chain ID
+	LegacyTxCutoff uint64 `json:"legacyTxCutoff,omitempty"`
+
+	// BetaGasPolicy charges host functions of contracts by gas.BetaPolicy
+	BetaGasPolicy uint64 `json:"betaGasPolicy,omitempty"`
+
+	// ContractUpgrade lets creator or admin of contract replace its contract by a transaction
+	ContractUpgrade uint64 `json:"contractUpgrade,omitempty"`
+}
+
+// override replaces heights by the non-zero heights of overrides
+func (heights UpgradeHeights) override(overrides UpgradeHeights) UpgradeHeights {
+	if overrides.LegacyTxCutoff > 0 {
+		heights.LegacyTxCutoff = overrides.LegacyTxCutoff
+	}
+	if overrides.BetaGasPolicy > 0 {
+		heights.BetaGasPolicy = overrides.BetaGasPolicy
+	}
+	if overrides.ContractUpgrade > 0 {
+		heights.ContractUpgrade = overrides.ContractUpgrade
+	}
+	return heights
+}
+
+// isActivated checks whether change of upgradeHeight takes effect at height
+func isActivated(upgradeHeight uint64, height uint64) bool {
+	return upgradeHeight > 0 && height >= upgradeHeight
+}
+
+// SetUpgradeHeights overrides upgrade heights of genesis, for chains whose genesis has no upgrade heights
+func (app *App) SetUpgradeHeights(overrides UpgradeHeights) {
+	app.upgradeOverrides = overrides
+	app.upgrades = app.upgrades.override(overrides)
+}
+
+// executingHeight returns height of the block which transactions are validated and executed in
+func (app *App) executingHeight() uint64 {
+	return app.Meta.LatestBlockHeight() + 1
+}
+
+// gasPolicy returns gas policy of transactions at executing height.
+// Gas station charging by gas.AlphaPolicy switches to gas.BetaPolicy from its upgrade height
+func (app *App) gasPolicy() gas.Policy {
+	policy := app.gasStation.GetPolicy()
+	if _, ok := policy.(*gas.AlphaPolicy); ok && isActivated(app.upgrades.BetaGasPolicy, app.executingHeight()) {
+		return &gas.BetaPolicy{}
+	}
+	return policy
+}
+
+// isContractUpgrade checks whether tx upgrades contract of its receiver.
+// Before contract upgrade height, contract of payload is ignored and tx invokes receiver
+func (app *App) isContractUpgrade(tx *crypto.Transaction) bool {
+	return tx.Receiver != crypto.EmptyAddress && len(tx.Payload.Contract) > 0 &&
+		isActivated(app.upgrades.ContractUpgrade, app.executingHeight())
+}
@@ -0,0 +1,49 @@
+package consensus
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// heightWorker runs work in background up to the height watermark raised by commits.
+// Watermarks raised while working are coalesced, work only runs for the latest one
+type heightWorker struct {
+	watermark uint64
+	signal    chan struct{}
+	done      chan struct{}
+	stopOnce  sync.Once
+}
+
+func newHeightWorker(name string, work func(height uint64) error) *heightWorker {
+	worker := &heightWorker{
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(worker.done)
+		for range worker.signal {
+			if err := work(atomic.LoadUint64(&worker.watermark)); err != nil {
+				log.Printf("Failed to %s: %v", name, err)
+			}
+		}
+	}()
+	return worker
+}
+
+// raise moves watermark up to height and wakes the worker if it is idle
+func (worker *heightWorker) raise(height uint64) {
+	atomic.StoreUint64(&worker.watermark, height)
+	select {
+	case worker.signal <- struct{}{}:
+	default:
+	}
+}
+
+// stop waits for pending work to finish and stops the worker, nil or stopped worker is ignored
+func (worker *heightWorker) stop() {
+	if worker != nil {
+		worker.stopOnce.Do(func() { close(worker.signal) })
+		<-worker.done
+	}
+}
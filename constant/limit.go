@@ -1,6 +1,7 @@
 package constant
 
 const (
-	MaxTransactionSize int = 1024 * 1024
-	MaxEngineCallDepth int = 64
+	MaxTransactionSize  int = 1024 * 1024
+	MaxEngineCallDepth  int = 64
+	MaxStorageIterators int = 64
 )
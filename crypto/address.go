@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 
 	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/QuoineFinancial/liquid-chain/crc16"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/blake2b"
@@ -118,6 +119,21 @@ func decodeAddressBytes(raw []byte) ([]byte, error) {
 	return payload, nil
 }
 
+// NewSaltedDeploymentAddress returns address of contract deployed by another contract with salt
+func NewSaltedDeploymentAddress(deployerAddress Address, salt common.Hash) Address {
+	deployerBytes, _ := rlp.EncodeToBytes([]interface{}{deployerAddress, salt})
+	res := blake2b.Sum256(deployerBytes)
+	return AddressFromPubKey(res[:])
+}
+
+// NewDeterministicDeploymentAddress returns address of contract deployed by deployer with salt,
+// which is known before deployment from the contract itself
+func NewDeterministicDeploymentAddress(deployerAddress Address, salt common.Hash, contractHash common.Hash) Address {
+	deployerBytes, _ := rlp.EncodeToBytes([]interface{}{deployerAddress, salt, contractHash})
+	res := blake2b.Sum256(deployerBytes)
+	return AddressFromPubKey(res[:])
+}
+
 // NewDeploymentAddress returns new contract deployment address
 func NewDeploymentAddress(senderAddress Address, senderNonce uint64) Address {
 	senderBytes, _ := rlp.EncodeToBytes([]interface{}{senderAddress, senderNonce})
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -144,6 +145,47 @@ func TestNewDeploymentAddress(t *testing.T) {
 	}
 }
 
+func TestNewSaltedDeploymentAddress(t *testing.T) {
+	deployer, _ := AddressFromString("LB5EPP7RST6IROFHLNKTLGKAFQTXGNY45CEAXPTGVT3K53ZXFMMAW575")
+	tests := []struct {
+		name string
+		salt common.Hash
+		want string
+	}{
+		{"empty salt", common.Hash{}, "LBMRNUFPOLNTJKIZ3EQ7OZA4KRIUZTYTHV7ZESMGGLMUUJFUBWHRXEEA"},
+		{"salt", common.BytesToHash([]byte{1}), "LDVB7EB2SQATVVEOSREEHFDME5SN67SLJ5W3ANGYIWCSOMPWK3NFETZZ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewSaltedDeploymentAddress(deployer, tt.salt); got.String() != tt.want {
+				t.Errorf("NewSaltedDeploymentAddress() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDeterministicDeploymentAddress(t *testing.T) {
+	deployer, _ := AddressFromString("LB5EPP7RST6IROFHLNKTLGKAFQTXGNY45CEAXPTGVT3K53ZXFMMAW575")
+	contractHash := common.BytesToHash([]byte{2})
+	tests := []struct {
+		name         string
+		salt         common.Hash
+		contractHash common.Hash
+		want         string
+	}{
+		{"empty salt", common.Hash{}, contractHash, "LCDUHD6R4D53FIJXKLTRXQMO7RBDFUOUAJYSOURJYTHJ6IAU74E2ZDUC"},
+		{"salt", common.BytesToHash([]byte{1}), contractHash, "LBQRIVBYXEKEUUNVDMROD4EDAAVKA6RGYG6SRQ6PUGSDL53XO5DEP2QR"},
+		{"contract", common.BytesToHash([]byte{1}), common.BytesToHash([]byte{3}), "LCL5PG3L7Q6VPA552E3B6TIMO4AOKH56B5SVOCRMQKQA32ISVJ7KG4IM"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDeterministicDeploymentAddress(deployer, tt.salt, tt.contractHash); got.String() != tt.want {
+				t.Errorf("NewDeterministicDeploymentAddress() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
 func TestAddress_setBytes(t *testing.T) {
 	tests := []struct {
 		name string
@@ -22,6 +22,25 @@ type Receipt struct {
 	Code        ReceiptCode `json:"code"`
 	Events      []*Event    `json:"events"`
 	PostState   common.Hash
+	// Returns holds return data of the call, if any. As RLP tail field its items are the
+	// remaining list elements, so receipts encoded before the field existed keep their hash
+	Returns [][]byte `json:"returns,omitempty" rlp:"tail"`
+}
+
+// SetReturnData keeps data returned through the return buffer, empty data is not kept
+func (receipt *Receipt) SetReturnData(data []byte) {
+	receipt.Returns = nil
+	if len(data) > 0 {
+		receipt.Returns = [][]byte{data}
+	}
+}
+
+// ReturnData returns data returned through the return buffer
+func (receipt *Receipt) ReturnData() []byte {
+	if len(receipt.Returns) == 0 {
+		return nil
+	}
+	return receipt.Returns[0]
 }
 
 // Encode returns bytes representation of receipt
@@ -10,4 +10,5 @@ const (
 	ReceiptCodeIgniteError      ReceiptCode = 0x2
 	ReceiptCodeContractNotFound ReceiptCode = 0x3
 	ReceiptCodeMethodNotFound   ReceiptCode = 0x4
+	ReceiptCodeUnauthorized     ReceiptCode = 0x5
 )
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/common"
+)
+
+func TestReceiptReturnData(t *testing.T) {
+	receipt := Receipt{
+		Transaction: common.HexToHash("5e6552f82be4fe44e5f6915ca37ca2de24085da0cc83385040b68ace94b6d213"),
+		Index:       1,
+		Result:      3,
+		GasUsed:     2,
+		Code:        ReceiptCodeOK,
+		Events:      []*Event{},
+	}
+
+	// Receipt without return data is encoded as before return data was added
+	legacy, _ := rlp.EncodeToBytes(struct {
+		Transaction common.Hash
+		Index       uint32
+		Result      uint64
+		GasUsed     uint32
+		Code        ReceiptCode
+		Events      []*Event
+		PostState   common.Hash
+	}{receipt.Transaction, receipt.Index, receipt.Result, receipt.GasUsed, receipt.Code, receipt.Events, receipt.PostState})
+	if encoded, _ := receipt.Encode(); !bytes.Equal(encoded, legacy) {
+		t.Errorf("Expect encoding %x, got %x", legacy, encoded)
+	}
+	decoded, err := DecodeReceipt(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ReturnData() != nil {
+		t.Errorf("Expect no return data, got %v", decoded.ReturnData())
+	}
+
+	receipt.SetReturnData([]byte{1, 2, 3})
+	encoded, _ := receipt.Encode()
+	if decoded, err = DecodeReceipt(encoded); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.ReturnData(), []byte{1, 2, 3}) {
+		t.Errorf("Expect return data %v, got %v", []byte{1, 2, 3}, decoded.ReturnData())
+	}
+}
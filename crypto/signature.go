@@ -13,16 +13,21 @@ func Sign(privateKey ed25519.PrivateKey, message []byte) []byte {
 	return ed25519.Sign(privateKey, message)
 }
 
-// GetSigHash returns hash for signing transaction
-func GetSigHash(tx *Transaction) common.Hash {
-	encoded, _ := rlp.EncodeToBytes([]interface{}{
+// GetSigHash returns hash for signing transaction.
+// chainID is only part of the hash since TxVersionChainID
+func GetSigHash(tx *Transaction, chainID string) common.Hash {
+	fields := []interface{}{
 		tx.Version,
 		tx.Sender,
 		tx.Receiver,
 		tx.Payload,
 		tx.GasPrice,
 		tx.GasLimit,
-	})
+	}
+	if tx.Version >= TxVersionChainID {
+		fields = append(fields, chainID)
+	}
+	encoded, _ := rlp.EncodeToBytes(fields)
 	return blake2b.Sum256(encoded)
 }
 
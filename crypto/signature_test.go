@@ -65,13 +65,34 @@ func TestGetSigHash(t *testing.T) {
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := GetSigHash(tt.args.tx); !cmp.Equal(got, tt.want) {
+			if got := GetSigHash(tt.args.tx, "liquid"); !cmp.Equal(got, tt.want) {
 				t.Errorf("GetSigHash() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestGetSigHashWithChainID(t *testing.T) {
+	tx := &Transaction{
+		Version: TxVersionChainID,
+		Sender: &TxSender{
+			Nonce:     uint64(0),
+			PublicKey: ed25519.NewKeyFromSeed(make([]byte, 32)).Public().(ed25519.PublicKey),
+		},
+		Payload:  &TxPayload{ID: GetMethodID("Transfer"), Args: []byte{4, 5, 6}},
+		GasPrice: 1,
+		GasLimit: 2,
+	}
+	if GetSigHash(tx, "mainnet") == GetSigHash(tx, "testnet") {
+		t.Error("GetSigHash() should differ between chains")
+	}
+
+	tx.Version = TxVersionLegacy
+	if GetSigHash(tx, "mainnet") != GetSigHash(tx, "testnet") {
+		t.Error("GetSigHash() of legacy transaction should not depend on chain")
+	}
+}
+
 func TestVerifySignature(t *testing.T) {
 	seedFirst := make([]byte, 32)
 	rand.Read(seedFirst)
@@ -8,6 +8,15 @@ import (
 	"golang.org/x/crypto/blake2b"
 )
 
+// Supported transaction versions
+const (
+	// TxVersionLegacy is signed without chain ID, accepted during migration to TxVersionChainID
+	TxVersionLegacy uint16 = 1
+
+	// TxVersionChainID mixes the chain ID into signing hash to prevent cross-chain replay
+	TxVersionChainID uint16 = 2
+)
+
 // TxSender is sender of transaction
 type TxSender struct {
 	PublicKey ed25519.PublicKey `json:"publicKey"`
@@ -19,6 +28,21 @@ type TxPayload struct {
 	ID       MethodID `json:"signature"`
 	Args     []byte   `json:"args"`
 	Contract []byte   `json:"contract"`
+	// Salt deploys contract at deterministic address, empty if contract is deployed at address of sender nonce
+	Salt []common.Hash `json:"salt,omitempty" rlp:"tail"`
+}
+
+// SetSalt sets salt to deploy contract at deterministic address
+func (payload *TxPayload) SetSalt(salt common.Hash) {
+	payload.Salt = []common.Hash{salt}
+}
+
+// GetSalt returns salt of deployment, false if contract is deployed at address of sender nonce
+func (payload *TxPayload) GetSalt() (common.Hash, bool) {
+	if len(payload.Salt) == 0 {
+		return common.EmptyHash, false
+	}
+	return payload.Salt[0], true
 }
 
 // Transaction is transaction of liquid-chain
@@ -46,6 +70,15 @@ func DecodeTransaction(raw []byte) (*Transaction, error) {
 	return &tx, nil
 }
 
+// DeploymentAddress returns address of contract deployed by transaction
+func (tx Transaction) DeploymentAddress() Address {
+	senderAddress := AddressFromPubKey(tx.Sender.PublicKey)
+	if salt, ok := tx.Payload.GetSalt(); ok {
+		return NewDeterministicDeploymentAddress(senderAddress, salt, blake2b.Sum256(tx.Payload.Contract))
+	}
+	return NewDeploymentAddress(senderAddress, tx.Sender.Nonce)
+}
+
 // Hash returns hash for storing transaction
 func (tx Transaction) Hash() common.Hash {
 	hash, _ := tx.Encode()
@@ -6,6 +6,7 @@ import (
 
 	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestTransaction_Serialize(t *testing.T) {
@@ -121,7 +122,7 @@ func TestTransaction_Deserialize(t *testing.T) {
 				t.Errorf("DecodeTransaction() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err == nil {
-				if equal := cmp.Equal((*tx), tt.want); !equal {
+				if equal := cmp.Equal((*tx), tt.want, cmpopts.EquateEmpty()); !equal {
 					t.Errorf("Transaction.Deserialize() %v, want %v", tx, tt.wantErr)
 				}
 			}
@@ -0,0 +1,49 @@
+package db
+
+import "fmt"
+
+// Backend is the name of a persistent database implementation
+type Backend string
+
+// Supported persistent database backends
+const (
+	// RocksDBBackend requires cgo and librocksdb
+	RocksDBBackend Backend = "rocksdb"
+
+	// LevelDBBackend is pure Go
+	LevelDBBackend Backend = "goleveldb"
+)
+
+// DefaultBackend keeps existing data directories readable
+const DefaultBackend = RocksDBBackend
+
+// NewDatabase opens the database at path with given backend
+func NewDatabase(backend Backend, path string) (Database, error) {
+	switch backend {
+	case RocksDBBackend:
+		return NewRocksDB(path), nil
+	case LevelDBBackend:
+		return NewLevelDB(path), nil
+	default:
+		return nil, fmt.Errorf("Unsupported database backend %s", backend)
+	}
+}
+
+// Copy writes all key-value pairs of source into target in batches of batchSize
+func Copy(target Database, source Reader, batchSize int) (int, error) {
+	count := 0
+	batch := target.NewBatch()
+	iterator := source.NewIterator(nil, nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		batch.Put(iterator.Key(), iterator.Value())
+		count++
+		if count%batchSize == 0 {
+			if err := batch.Write(); err != nil {
+				return count, err
+			}
+			batch.Reset()
+		}
+	}
+	return count, batch.Write()
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"encoding/hex"
+	"sync"
+)
+
+// BufferedDatabase keeps writes in memory until Flush.
+// Reads see buffered writes before the parent database
+type BufferedDatabase struct {
+	parent  Database
+	mutex   sync.RWMutex
+	pending map[string]keyValue
+}
+
+// NewBufferedDatabase returns a write buffer on top of parent
+func NewBufferedDatabase(parent Database) *BufferedDatabase {
+	return &BufferedDatabase{
+		parent:  parent,
+		pending: make(map[string]keyValue),
+	}
+}
+
+// Get returns the value based on key
+func (db *BufferedDatabase) Get(key []byte) []byte {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	return getBuffered(db.pending, db.parent, key)
+}
+
+// Has returns whether key exists
+func (db *BufferedDatabase) Has(key []byte) bool {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	return hasBuffered(db.pending, db.parent, key)
+}
+
+// NewIterator iterates buffered and parent key-value pairs in range
+func (db *BufferedDatabase) NewIterator(start []byte, end []byte) Iterator {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	return newMergedIterator(pendingInRange(db.pending, start, end), db.parent.NewIterator(start, end))
+}
+
+// Put buffers an key-value pair
+func (db *BufferedDatabase) Put(key []byte, value []byte) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.pending[hex.EncodeToString(key)] = keyValue{key: append([]byte{}, key...), value: append([]byte{}, value...)}
+}
+
+// Delete buffers deletion of key
+func (db *BufferedDatabase) Delete(key []byte) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.pending[hex.EncodeToString(key)] = keyValue{key: append([]byte{}, key...), deleted: true}
+}
+
+// NewBatch returns a batch of writes to the buffer
+func (db *BufferedDatabase) NewBatch() Batch {
+	return &bufferedBatch{db: db}
+}
+
+// NewSnapshot returns a read-only view of buffered writes and parent snapshot
+func (db *BufferedDatabase) NewSnapshot() Snapshot {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	pending := make(map[string]keyValue, len(db.pending))
+	for key, write := range db.pending {
+		pending[key] = write
+	}
+	return &bufferedSnapshot{pending: pending, parent: db.parent.NewSnapshot()}
+}
+
+// Flush writes all buffered key-value pairs to parent in one batch.
+// Buffered writes are kept if parent fails to write them
+func (db *BufferedDatabase) Flush() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if len(db.pending) == 0 {
+		return nil
+	}
+	batch := db.parent.NewBatch()
+	for _, write := range db.pending {
+		if write.deleted {
+			batch.Delete(write.key)
+		} else {
+			batch.Put(write.key, write.value)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	db.pending = make(map[string]keyValue)
+	return nil
+}
+
+// Discard drops all buffered key-value pairs
+func (db *BufferedDatabase) Discard() {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.pending = make(map[string]keyValue)
+}
+
+// Close closes parent database, buffered writes are discarded
+func (db *BufferedDatabase) Close() {
+	db.Discard()
+	db.parent.Close()
+}
+
+func getBuffered(pending map[string]keyValue, parent Reader, key []byte) []byte {
+	if write, ok := pending[hex.EncodeToString(key)]; ok {
+		if write.deleted {
+			return nil
+		}
+		return write.value
+	}
+	return parent.Get(key)
+}
+
+func hasBuffered(pending map[string]keyValue, parent Reader, key []byte) bool {
+	if write, ok := pending[hex.EncodeToString(key)]; ok {
+		return !write.deleted
+	}
+	return parent.Has(key)
+}
+
+func pendingInRange(pending map[string]keyValue, start []byte, end []byte) []keyValue {
+	pairs := []keyValue{}
+	for _, write := range pending {
+		if inRange(write.key, start, end) {
+			pairs = append(pairs, write)
+		}
+	}
+	return pairs
+}
+
+type bufferedSnapshot struct {
+	pending map[string]keyValue
+	parent  Snapshot
+}
+
+func (snapshot *bufferedSnapshot) Get(key []byte) []byte {
+	return getBuffered(snapshot.pending, snapshot.parent, key)
+}
+
+func (snapshot *bufferedSnapshot) Has(key []byte) bool {
+	return hasBuffered(snapshot.pending, snapshot.parent, key)
+}
+
+func (snapshot *bufferedSnapshot) NewIterator(start []byte, end []byte) Iterator {
+	return newMergedIterator(pendingInRange(snapshot.pending, start, end), snapshot.parent.NewIterator(start, end))
+}
+
+func (snapshot *bufferedSnapshot) Release() {
+	snapshot.parent.Release()
+}
+
+type bufferedBatch struct {
+	db     *BufferedDatabase
+	writes []keyValue
+}
+
+func (batch *bufferedBatch) Put(key []byte, value []byte) {
+	batch.writes = append(batch.writes, keyValue{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+}
+
+func (batch *bufferedBatch) Delete(key []byte) {
+	batch.writes = append(batch.writes, keyValue{key: append([]byte{}, key...), deleted: true})
+}
+
+func (batch *bufferedBatch) Write() error {
+	batch.db.mutex.Lock()
+	defer batch.db.mutex.Unlock()
+	for _, write := range batch.writes {
+		batch.db.pending[hex.EncodeToString(write.key)] = write
+	}
+	return nil
+}
+
+func (batch *bufferedBatch) Reset() {
+	batch.writes = batch.writes[:0]
+}
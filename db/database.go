@@ -1,7 +1,64 @@
 package db
 
-// Database generics inteface
-type Database interface {
+// Reader reads key-value pairs
+type Reader interface {
 	Get(key []byte) []byte
+	Has(key []byte) bool
+
+	// NewIterator iterates key-value pairs in range [start, end) by key order.
+	// Nil start or end leaves the range open on that side
+	NewIterator(start []byte, end []byte) Iterator
+}
+
+// Writer writes key-value pairs
+type Writer interface {
 	Put(key []byte, value []byte)
+	Delete(key []byte)
+}
+
+// Database generics inteface
+type Database interface {
+	Reader
+	Writer
+	NewBatch() Batch
+	NewSnapshot() Snapshot
+	Close()
+}
+
+// Batch collects writes and applies them to database atomically
+type Batch interface {
+	Writer
+	Write() error
+	Reset()
+}
+
+// Iterator walks over key-value pairs, it must be released after use
+type Iterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Release()
+}
+
+// Snapshot is a read-only view of database at the time it is taken, it must be released after use
+type Snapshot interface {
+	Reader
+	Release()
+}
+
+// NewPrefixIterator iterates key-value pairs whose key starts with prefix
+func NewPrefixIterator(reader Reader, prefix []byte) Iterator {
+	return reader.NewIterator(prefix, prefixEnd(prefix))
+}
+
+// prefixEnd returns the smallest key greater than all keys starting with prefix
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
 }
@@ -2,8 +2,11 @@ package db
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 var testVector = []struct {
@@ -55,3 +58,254 @@ func TestMemoryDB(t *testing.T) {
 		}
 	}
 }
+
+func TestBatch(t *testing.T) {
+	path := "./test-batch-db"
+	defer os.RemoveAll(path)
+
+	for _, db := range []Database{NewMemoryDB(), NewRocksDB(path)} {
+		batch := db.NewBatch()
+		for _, item := range testVector {
+			batch.Put([]byte(item.key), []byte(item.value))
+		}
+
+		// Nothing is written before Write
+		for _, item := range testVector {
+			if actual := db.Get([]byte(item.key)); len(actual) != 0 {
+				t.Errorf("Value of %s is written before batch write: %v", item.key, actual)
+			}
+		}
+
+		batch.Write()
+		for _, item := range testVector {
+			actual := db.Get([]byte(item.key))
+			if !bytes.Equal(actual, []byte(item.value)) {
+				t.Errorf("Value getting from db is different from expected. Expected: %v. Actual: %v", item.value, actual)
+			}
+		}
+
+		batch.Reset()
+		batch.Put([]byte("hello"), []byte("again"))
+		batch.Write()
+		if actual := db.Get([]byte("hello")); !bytes.Equal(actual, []byte("again")) {
+			t.Errorf("Value of reset batch is not written: %v", actual)
+		}
+	}
+}
+
+func TestBufferedDatabase(t *testing.T) {
+	parent := NewMemoryDB()
+	parent.Put([]byte("parent"), []byte("value"))
+	db := NewBufferedDatabase(parent)
+
+	for _, item := range testVector {
+		db.Put([]byte(item.key), []byte(item.value))
+	}
+	batch := db.NewBatch()
+	batch.Put([]byte("batch"), []byte("value"))
+	batch.Write()
+
+	// Reads see both buffered and parent values
+	for _, item := range append(testVector, struct{ key, value string }{"parent", "value"}, struct{ key, value string }{"batch", "value"}) {
+		actual := db.Get([]byte(item.key))
+		if !bytes.Equal(actual, []byte(item.value)) {
+			t.Errorf("Value getting from db is different from expected. Expected: %v. Actual: %v", item.value, actual)
+		}
+	}
+	if actual := parent.Get([]byte("hello")); len(actual) != 0 {
+		t.Errorf("Buffered value is written to parent before flush: %v", actual)
+	}
+
+	db.Flush()
+	for _, item := range testVector {
+		actual := parent.Get([]byte(item.key))
+		if !bytes.Equal(actual, []byte(item.value)) {
+			t.Errorf("Value flushed to parent is different from expected. Expected: %v. Actual: %v", item.value, actual)
+		}
+	}
+
+	db.Put([]byte("discarded"), []byte("value"))
+	db.Discard()
+	if actual := db.Get([]byte("discarded")); len(actual) != 0 {
+		t.Errorf("Discarded value is still readable: %v", actual)
+	}
+
+	// Failed flush keeps buffered values
+	failing := NewBufferedDatabase(&failingDatabase{NewMemoryDB()})
+	failing.Put([]byte("kept"), []byte("value"))
+	if err := failing.Flush(); err == nil {
+		t.Error("Flush does not return write error of parent")
+	}
+	if actual := failing.Get([]byte("kept")); !bytes.Equal(actual, []byte("value")) {
+		t.Errorf("Buffered value is dropped after failed flush: %v", actual)
+	}
+}
+
+// failingDatabase fails to write batches
+type failingDatabase struct {
+	*MemoryDB
+}
+
+func (db *failingDatabase) NewBatch() Batch {
+	return &failingBatch{db.MemoryDB.NewBatch()}
+}
+
+type failingBatch struct {
+	Batch
+}
+
+func (batch *failingBatch) Write() error {
+	return errors.New("disk failure")
+}
+
+func collectIterator(it Iterator) []string {
+	defer it.Release()
+	pairs := []string{}
+	for it.Next() {
+		pairs = append(pairs, string(it.Key())+"="+string(it.Value()))
+	}
+	return pairs
+}
+
+func testDatabase(t *testing.T, db Database) {
+	for _, item := range testVector {
+		db.Put([]byte(item.key), []byte(item.value))
+	}
+
+	// Has and Delete
+	if !db.Has([]byte("hello")) || db.Has([]byte("missing")) {
+		t.Error("Has returns wrong existence")
+	}
+	db.Put([]byte("empty"), []byte{})
+	if !db.Has([]byte("empty")) {
+		t.Error("Has returns false for empty value")
+	}
+	db.Delete([]byte("empty"))
+	if db.Has([]byte("empty")) || db.Get([]byte("empty")) != nil {
+		t.Error("Deleted key still exists")
+	}
+
+	// Iterators
+	all := []string{"block=chain", "dang=nguyen", "hello=world", "merkle=tree"}
+	if got := collectIterator(db.NewIterator(nil, nil)); !cmp.Equal(got, all) {
+		t.Errorf("NewIterator(nil, nil) = %v, want %v", got, all)
+	}
+	if got := collectIterator(db.NewIterator([]byte("c"), []byte("merkle"))); !cmp.Equal(got, all[1:3]) {
+		t.Errorf("NewIterator(c, merkle) = %v, want %v", got, all[1:3])
+	}
+	db.Put([]byte("helloworld"), []byte("again"))
+	want := []string{"hello=world", "helloworld=again"}
+	if got := collectIterator(NewPrefixIterator(db, []byte("hello"))); !cmp.Equal(got, want) {
+		t.Errorf("NewPrefixIterator(hello) = %v, want %v", got, want)
+	}
+
+	// Snapshot is not affected by later writes
+	snapshot := db.NewSnapshot()
+	defer snapshot.Release()
+	db.Put([]byte("hello"), []byte("changed"))
+	db.Delete([]byte("helloworld"))
+	if got := snapshot.Get([]byte("hello")); !bytes.Equal(got, []byte("world")) {
+		t.Errorf("Snapshot Get(hello) = %s, want world", got)
+	}
+	if !snapshot.Has([]byte("helloworld")) {
+		t.Error("Snapshot loses key deleted after it")
+	}
+	if got := collectIterator(NewPrefixIterator(snapshot, []byte("hello"))); !cmp.Equal(got, want) {
+		t.Errorf("Snapshot NewPrefixIterator(hello) = %v, want %v", got, want)
+	}
+	if got := collectIterator(NewPrefixIterator(db, []byte("hello"))); !cmp.Equal(got, []string{"hello=changed"}) {
+		t.Errorf("NewPrefixIterator(hello) after write = %v", got)
+	}
+
+	// Batch delete
+	batch := db.NewBatch()
+	batch.Delete([]byte("hello"))
+	batch.Write()
+	if db.Has([]byte("hello")) {
+		t.Error("Key deleted by batch still exists")
+	}
+}
+
+func TestDatabaseImplementations(t *testing.T) {
+	path := "./test-impl-db"
+	defer os.RemoveAll(path)
+
+	t.Run("MemoryDB", func(t *testing.T) {
+		db := NewMemoryDB()
+		defer db.Close()
+		testDatabase(t, db)
+	})
+
+	t.Run("RocksDB", func(t *testing.T) {
+		db := NewRocksDB(path)
+		defer db.Close()
+		testDatabase(t, db)
+	})
+
+	t.Run("LevelDB", func(t *testing.T) {
+		db := NewLevelDB(path + "-level")
+		defer os.RemoveAll(path + "-level")
+		defer db.Close()
+		testDatabase(t, db)
+	})
+
+	t.Run("BufferedDatabase", func(t *testing.T) {
+		parent := NewMemoryDB()
+		parent.Put([]byte("block"), []byte("chain"))
+		parent.Put([]byte("hello"), []byte("parent"))
+		db := NewBufferedDatabase(parent)
+		defer db.Close()
+		testDatabase(t, db)
+
+		db.Flush()
+		if parent.Has([]byte("hello")) || !parent.Has([]byte("merkle")) {
+			t.Error("Flush does not apply buffered writes and deletes to parent")
+		}
+	})
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix []byte
+		want   []byte
+	}{
+		{[]byte{1, 2}, []byte{1, 3}},
+		{[]byte{1, 0xff}, []byte{2}},
+		{[]byte{0xff, 0xff}, nil},
+		{[]byte{}, nil},
+	}
+	for _, tt := range tests {
+		if got := prefixEnd(tt.prefix); !bytes.Equal(got, tt.want) {
+			t.Errorf("prefixEnd(%v) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	source := NewMemoryDB()
+	for _, item := range testVector {
+		source.Put([]byte(item.key), []byte(item.value))
+	}
+	target := NewMemoryDB()
+	if count, err := Copy(target, source, 3); err != nil || count != len(testVector) {
+		t.Errorf("Copy() = %d, %v, want %d", count, err, len(testVector))
+	}
+	for _, item := range testVector {
+		if got := target.Get([]byte(item.key)); !bytes.Equal(got, []byte(item.value)) {
+			t.Errorf("Copied value of %s = %s, want %s", item.key, got, item.value)
+		}
+	}
+}
+
+func TestNewDatabase(t *testing.T) {
+	path := "./test-new-db"
+	defer os.RemoveAll(path)
+	database, err := NewDatabase(LevelDBBackend, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.Close()
+	if _, err := NewDatabase(Backend("unknown"), path); err == nil {
+		t.Error("NewDatabase accepts unknown backend")
+	}
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"bytes"
+	"sort"
+)
+
+type keyValue struct {
+	key     []byte
+	value   []byte
+	deleted bool
+}
+
+func inRange(key, start, end []byte) bool {
+	return (start == nil || bytes.Compare(key, start) >= 0) && (end == nil || bytes.Compare(key, end) < 0)
+}
+
+func sortKeyValues(pairs []keyValue) {
+	sort.Slice(pairs, func(i, j int) bool {
+		return bytes.Compare(pairs[i].key, pairs[j].key) < 0
+	})
+}
+
+// sliceIterator iterates over sorted key-value pairs
+type sliceIterator struct {
+	pairs []keyValue
+	index int
+}
+
+func newSliceIterator(pairs []keyValue) *sliceIterator {
+	sortKeyValues(pairs)
+	return &sliceIterator{pairs: pairs, index: -1}
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.index < len(it.pairs) {
+		it.index++
+	}
+	return it.index < len(it.pairs)
+}
+
+func (it *sliceIterator) Key() []byte {
+	if it.index < 0 || it.index >= len(it.pairs) {
+		return nil
+	}
+	return it.pairs[it.index].key
+}
+
+func (it *sliceIterator) Value() []byte {
+	if it.index < 0 || it.index >= len(it.pairs) {
+		return nil
+	}
+	return it.pairs[it.index].value
+}
+
+func (it *sliceIterator) Release() {
+	it.pairs = nil
+}
+
+// mergedIterator iterates over sorted pending writes on top of parent iterator.
+// Pending writes take precedence, deleted pending keys are skipped
+type mergedIterator struct {
+	pending  *sliceIterator
+	parent   Iterator
+	hasNext  [2]bool
+	key      []byte
+	value    []byte
+	released bool
+}
+
+func newMergedIterator(pending []keyValue, parent Iterator) *mergedIterator {
+	it := &mergedIterator{pending: newSliceIterator(pending), parent: parent}
+	it.hasNext = [2]bool{it.pending.Next(), it.parent.Next()}
+	return it
+}
+
+func (it *mergedIterator) Next() bool {
+	for it.hasNext[0] || it.hasNext[1] {
+		var current keyValue
+		switch {
+		case it.hasNext[0] && it.hasNext[1]:
+			switch bytes.Compare(it.pending.Key(), it.parent.Key()) {
+			case -1:
+				current = it.pending.pairs[it.pending.index]
+				it.hasNext[0] = it.pending.Next()
+			case 0:
+				current = it.pending.pairs[it.pending.index]
+				it.hasNext[0] = it.pending.Next()
+				it.hasNext[1] = it.parent.Next()
+			default:
+				current = keyValue{key: it.parent.Key(), value: it.parent.Value()}
+				it.hasNext[1] = it.parent.Next()
+			}
+		case it.hasNext[0]:
+			current = it.pending.pairs[it.pending.index]
+			it.hasNext[0] = it.pending.Next()
+		default:
+			current = keyValue{key: it.parent.Key(), value: it.parent.Value()}
+			it.hasNext[1] = it.parent.Next()
+		}
+		if !current.deleted {
+			it.key, it.value = current.key, current.value
+			return true
+		}
+	}
+	it.key, it.value = nil, nil
+	return false
+}
+
+func (it *mergedIterator) Key() []byte {
+	return it.key
+}
+
+func (it *mergedIterator) Value() []byte {
+	return it.value
+}
+
+func (it *mergedIterator) Release() {
+	if !it.released {
+		it.released = true
+		it.pending.Release()
+		it.parent.Release()
+	}
+}
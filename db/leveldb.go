@@ -0,0 +1,175 @@
+package db
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// LevelDB is the pure Go database backed by goleveldb
+type LevelDB struct {
+	instance *leveldb.DB
+}
+
+// NewLevelDB returns a new instance of the LevelDB
+func NewLevelDB(path string) *LevelDB {
+	instance, err := leveldb.OpenFile(path, &opt.Options{
+		BlockCacheCapacity: 256 * opt.MiB,
+		WriteBuffer:        64 * opt.MiB,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &LevelDB{instance}
+}
+
+// Get returns the value based on key
+func (db *LevelDB) Get(key []byte) []byte {
+	return getLevelDB(db.instance, key)
+}
+
+// Has returns whether key exists
+func (db *LevelDB) Has(key []byte) bool {
+	has, err := db.instance.Has(key, nil)
+	if err != nil {
+		panic(err)
+	}
+	return has
+}
+
+// Put inserts an key-value pair to database
+func (db *LevelDB) Put(key []byte, value []byte) {
+	if err := db.instance.Put(key, value, nil); err != nil {
+		panic(err)
+	}
+}
+
+// Delete removes key from database
+func (db *LevelDB) Delete(key []byte) {
+	if err := db.instance.Delete(key, nil); err != nil {
+		panic(err)
+	}
+}
+
+// NewIterator iterates key-value pairs in range
+func (db *LevelDB) NewIterator(start []byte, end []byte) Iterator {
+	return &levelIterator{source: db.instance.NewIterator(&util.Range{Start: start, Limit: end}, nil)}
+}
+
+// NewBatch returns a batch of writes to LevelDB
+func (db *LevelDB) NewBatch() Batch {
+	return &levelBatch{db: db, batch: new(leveldb.Batch)}
+}
+
+// NewSnapshot returns a consistent read-only view of LevelDB
+func (db *LevelDB) NewSnapshot() Snapshot {
+	snapshot, err := db.instance.GetSnapshot()
+	if err != nil {
+		panic(err)
+	}
+	return &levelSnapshot{snapshot}
+}
+
+// Close closes the LevelDB instance
+func (db *LevelDB) Close() {
+	if err := db.instance.Close(); err != nil {
+		panic(err)
+	}
+}
+
+type levelReader interface {
+	Get(key []byte, ro *opt.ReadOptions) ([]byte, error)
+}
+
+func getLevelDB(reader levelReader, key []byte) []byte {
+	value, err := reader.Get(key, nil)
+	if err == errors.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		panic(err)
+	}
+	// Keep empty value distinguishable from missing key
+	if value == nil {
+		return []byte{}
+	}
+	return value
+}
+
+type levelIterator struct {
+	source iterator.Iterator
+	key    []byte
+	value  []byte
+}
+
+func (it *levelIterator) Next() bool {
+	it.key, it.value = nil, nil
+	if !it.source.Next() {
+		if err := it.source.Error(); err != nil {
+			panic(err)
+		}
+		return false
+	}
+	it.key = append([]byte{}, it.source.Key()...)
+	it.value = append([]byte{}, it.source.Value()...)
+	return true
+}
+
+func (it *levelIterator) Key() []byte {
+	return it.key
+}
+
+func (it *levelIterator) Value() []byte {
+	return it.value
+}
+
+func (it *levelIterator) Release() {
+	it.source.Release()
+}
+
+type levelSnapshot struct {
+	snapshot *leveldb.Snapshot
+}
+
+func (snapshot *levelSnapshot) Get(key []byte) []byte {
+	return getLevelDB(snapshot.snapshot, key)
+}
+
+func (snapshot *levelSnapshot) Has(key []byte) bool {
+	has, err := snapshot.snapshot.Has(key, nil)
+	if err != nil {
+		panic(err)
+	}
+	return has
+}
+
+func (snapshot *levelSnapshot) NewIterator(start []byte, end []byte) Iterator {
+	return &levelIterator{source: snapshot.snapshot.NewIterator(&util.Range{Start: start, Limit: end}, nil)}
+}
+
+func (snapshot *levelSnapshot) Release() {
+	snapshot.snapshot.Release()
+}
+
+type levelBatch struct {
+	db    *LevelDB
+	batch *leveldb.Batch
+}
+
+func (batch *levelBatch) Put(key []byte, value []byte) {
+	batch.batch.Put(key, value)
+}
+
+func (batch *levelBatch) Delete(key []byte) {
+	batch.batch.Delete(key)
+}
+
+func (batch *levelBatch) Write() error {
+	return batch.db.instance.Write(batch.batch, &opt.WriteOptions{Sync: true})
+}
+
+func (batch *levelBatch) Reset() {
+	batch.batch.Reset()
+}
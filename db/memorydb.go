@@ -2,10 +2,12 @@ package db
 
 import (
 	"encoding/hex"
+	"sync"
 )
 
 // MemoryDB simple memory database
 type MemoryDB struct {
+	mutex sync.RWMutex
 	cache map[string][]byte
 }
 
@@ -18,10 +20,98 @@ func NewMemoryDB() *MemoryDB {
 
 // Get returns the value based on key
 func (db *MemoryDB) Get(key []byte) []byte {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
 	return db.cache[hex.EncodeToString(key)]
 }
 
+// Has returns whether key exists
+func (db *MemoryDB) Has(key []byte) bool {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	_, ok := db.cache[hex.EncodeToString(key)]
+	return ok
+}
+
 // Put inserts an key-value pair to database
 func (db *MemoryDB) Put(key []byte, value []byte) {
-	db.cache[hex.EncodeToString(key)] = value
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.cache[hex.EncodeToString(key)] = append([]byte{}, value...)
+}
+
+// Delete removes key from database
+func (db *MemoryDB) Delete(key []byte) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	delete(db.cache, hex.EncodeToString(key))
+}
+
+// NewIterator iterates over a copy of key-value pairs in range
+func (db *MemoryDB) NewIterator(start []byte, end []byte) Iterator {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	pairs := []keyValue{}
+	for hexKey, value := range db.cache {
+		key, _ := hex.DecodeString(hexKey)
+		if inRange(key, start, end) {
+			pairs = append(pairs, keyValue{key: key, value: value})
+		}
+	}
+	return newSliceIterator(pairs)
+}
+
+// NewBatch returns a batch of writes to memory database
+func (db *MemoryDB) NewBatch() Batch {
+	return &memoryBatch{db: db}
+}
+
+// NewSnapshot returns a copy of memory database
+func (db *MemoryDB) NewSnapshot() Snapshot {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	snapshot := NewMemoryDB()
+	for key, value := range db.cache {
+		snapshot.cache[key] = value
+	}
+	return &memorySnapshot{snapshot}
+}
+
+// Close does nothing for memory database
+func (db *MemoryDB) Close() {}
+
+type memorySnapshot struct {
+	*MemoryDB
+}
+
+func (snapshot *memorySnapshot) Release() {}
+
+type memoryBatch struct {
+	db     *MemoryDB
+	writes []keyValue
+}
+
+func (batch *memoryBatch) Put(key []byte, value []byte) {
+	batch.writes = append(batch.writes, keyValue{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+}
+
+func (batch *memoryBatch) Delete(key []byte) {
+	batch.writes = append(batch.writes, keyValue{key: append([]byte{}, key...), deleted: true})
+}
+
+func (batch *memoryBatch) Write() error {
+	batch.db.mutex.Lock()
+	defer batch.db.mutex.Unlock()
+	for _, write := range batch.writes {
+		if write.deleted {
+			delete(batch.db.cache, hex.EncodeToString(write.key))
+		} else {
+			batch.db.cache[hex.EncodeToString(write.key)] = write.value
+		}
+	}
+	return nil
+}
+
+func (batch *memoryBatch) Reset() {
+	batch.writes = batch.writes[:0]
 }
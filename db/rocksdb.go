@@ -1,12 +1,21 @@
+//go:build cgo
+// +build cgo
+
 package db
 
 import (
+	"bytes"
+
 	"github.com/linxGnu/grocksdb"
 )
 
 // RocksDB use map to store and retrieve value
 type RocksDB struct {
 	instance *grocksdb.DB
+	options  *grocksdb.Options
+	ro       *grocksdb.ReadOptions
+	wo       *grocksdb.WriteOptions
+	syncWo   *grocksdb.WriteOptions
 }
 
 // NewRocksDB returns a new instance of the RocksDB
@@ -20,29 +29,192 @@ func NewRocksDB(path string) *RocksDB {
 	if err != nil {
 		panic(err)
 	}
-	return &RocksDB{instance}
+
+	ro := grocksdb.NewDefaultReadOptions()
+	ro.SetFillCache(true)
+	wo := grocksdb.NewDefaultWriteOptions()
+	wo.SetSync(false)
+	syncWo := grocksdb.NewDefaultWriteOptions()
+	syncWo.SetSync(true)
+	return &RocksDB{instance, opts, ro, wo, syncWo}
 }
 
 // Get returns the value based on key
 func (db *RocksDB) Get(key []byte) []byte {
-	ro := grocksdb.NewDefaultReadOptions()
-	ro.SetFillCache(true)
-	value, err := db.instance.Get(ro, key)
+	value, err := db.instance.GetBytes(db.ro, key)
 	if err != nil {
 		panic(err)
 	}
-	return value.Data()
+	return value
+}
+
+// Has returns whether key exists
+func (db *RocksDB) Has(key []byte) bool {
+	return db.Get(key) != nil
 }
 
 // Put inserts an key-value pair to database
 func (db *RocksDB) Put(key []byte, value []byte) {
-	wo := grocksdb.NewDefaultWriteOptions()
-	wo.SetSync(false)
-	if err := db.instance.Put(wo, key, value); err != nil {
+	if err := db.instance.Put(db.wo, key, value); err != nil {
 		panic(err)
 	}
 }
 
+// Delete removes key from database
+func (db *RocksDB) Delete(key []byte) {
+	if err := db.instance.Delete(db.wo, key); err != nil {
+		panic(err)
+	}
+}
+
+// NewIterator iterates key-value pairs in range
+func (db *RocksDB) NewIterator(start []byte, end []byte) Iterator {
+	return newRocksIterator(db.instance, nil, start, end)
+}
+
+// NewBatch returns a batch of writes to RocksDB
+func (db *RocksDB) NewBatch() Batch {
+	return &rocksBatch{db: db}
+}
+
+// NewSnapshot returns a consistent read-only view of RocksDB
+func (db *RocksDB) NewSnapshot() Snapshot {
+	snapshot := db.instance.NewSnapshot()
+	ro := grocksdb.NewDefaultReadOptions()
+	ro.SetSnapshot(snapshot)
+	return &rocksSnapshot{db: db, snapshot: snapshot, ro: ro}
+}
+
+// Close closes the RocksDB instance
 func (db *RocksDB) Close() {
 	db.instance.Close()
+	db.ro.Destroy()
+	db.wo.Destroy()
+	db.syncWo.Destroy()
+	db.options.Destroy()
+}
+
+type rocksIterator struct {
+	source  *grocksdb.Iterator
+	ro      *grocksdb.ReadOptions
+	end     []byte
+	started bool
+	key     []byte
+	value   []byte
+}
+
+func newRocksIterator(instance *grocksdb.DB, snapshot *grocksdb.Snapshot, start []byte, end []byte) *rocksIterator {
+	ro := grocksdb.NewDefaultReadOptions()
+	ro.SetFillCache(false)
+	if snapshot != nil {
+		ro.SetSnapshot(snapshot)
+	}
+	source := instance.NewIterator(ro)
+	if start == nil {
+		source.SeekToFirst()
+	} else {
+		source.Seek(start)
+	}
+	return &rocksIterator{source: source, ro: ro, end: end}
+}
+
+func (it *rocksIterator) Next() bool {
+	if it.started {
+		it.source.Next()
+	}
+	it.started = true
+	it.key, it.value = nil, nil
+	if !it.source.Valid() {
+		if err := it.source.Err(); err != nil {
+			panic(err)
+		}
+		return false
+	}
+
+	key := it.source.Key()
+	defer key.Free()
+	if it.end != nil && bytes.Compare(key.Data(), it.end) >= 0 {
+		return false
+	}
+	value := it.source.Value()
+	defer value.Free()
+	it.key = append([]byte{}, key.Data()...)
+	it.value = append([]byte{}, value.Data()...)
+	return true
+}
+
+func (it *rocksIterator) Key() []byte {
+	return it.key
+}
+
+func (it *rocksIterator) Value() []byte {
+	return it.value
+}
+
+func (it *rocksIterator) Release() {
+	if it.source != nil {
+		it.source.Close()
+		it.ro.Destroy()
+		it.source = nil
+	}
+}
+
+type rocksSnapshot struct {
+	db       *RocksDB
+	snapshot *grocksdb.Snapshot
+	ro       *grocksdb.ReadOptions
+}
+
+func (snapshot *rocksSnapshot) Get(key []byte) []byte {
+	value, err := snapshot.db.instance.GetBytes(snapshot.ro, key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (snapshot *rocksSnapshot) Has(key []byte) bool {
+	return snapshot.Get(key) != nil
+}
+
+func (snapshot *rocksSnapshot) NewIterator(start []byte, end []byte) Iterator {
+	return newRocksIterator(snapshot.db.instance, snapshot.snapshot, start, end)
+}
+
+func (snapshot *rocksSnapshot) Release() {
+	if snapshot.snapshot != nil {
+		snapshot.db.instance.ReleaseSnapshot(snapshot.snapshot)
+		snapshot.ro.Destroy()
+		snapshot.snapshot = nil
+	}
+}
+
+type rocksBatch struct {
+	db     *RocksDB
+	writes []keyValue
+}
+
+func (batch *rocksBatch) Put(key []byte, value []byte) {
+	batch.writes = append(batch.writes, keyValue{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+}
+
+func (batch *rocksBatch) Delete(key []byte) {
+	batch.writes = append(batch.writes, keyValue{key: append([]byte{}, key...), deleted: true})
+}
+
+func (batch *rocksBatch) Write() error {
+	wb := grocksdb.NewWriteBatch()
+	defer wb.Destroy()
+	for _, write := range batch.writes {
+		if write.deleted {
+			wb.Delete(write.key)
+		} else {
+			wb.Put(write.key, write.value)
+		}
+	}
+	return batch.db.instance.Write(batch.db.syncWo, wb)
+}
+
+func (batch *rocksBatch) Reset() {
+	batch.writes = batch.writes[:0]
 }
@@ -0,0 +1,14 @@
+//go:build !cgo
+// +build !cgo
+
+package db
+
+// RocksDB is not available without cgo
+type RocksDB struct {
+	Database
+}
+
+// NewRocksDB panics since RocksDB requires cgo, use LevelDB instead
+func NewRocksDB(path string) *RocksDB {
+	panic("RocksDB backend requires cgo, use goleveldb backend instead")
+}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"encoding/binary"
 	"errors"
@@ -8,9 +9,12 @@ import (
 
 	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
 	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/common"
 	"github.com/QuoineFinancial/liquid-chain/constant"
 	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/gas"
 	"github.com/vertexdlt/vertexvm/vm"
+	vertex "github.com/vertexdlt/vertexvm/vm"
 	"golang.org/x/crypto/blake2b"
 )
 
@@ -23,10 +27,13 @@ func readAt(vm *vm.VM, ptr, size int) ([]byte, error) {
 }
 
 func (engine *Engine) chainStorageSet(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := engine.requireWritable(); err != nil {
+		return 0, err
+	}
 	keyPtr, keySize := int(args[0]), int(args[1])
 	valuePtr, valueSize := int(args[2]), int(args[3])
 	// Burn gas before actually execute
-	cost := engine.gasPolicy.GetCostForStorage(valueSize)
+	cost := engine.gasPolicy.GetCostForStorageKey(keySize) + engine.gasPolicy.GetCostForStorage(valueSize)
 	err := vm.BurnGas(cost)
 	if err != nil {
 		return 0, err
@@ -45,6 +52,10 @@ func (engine *Engine) chainStorageSet(vm *vm.VM, args ...uint64) (uint64, error)
 
 func (engine *Engine) chainStorageGet(vm *vm.VM, args ...uint64) (uint64, error) {
 	keyPtr, keySize := int(args[0]), int(args[1])
+	// Burn gas before actually execute
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForStorageRead() + engine.gasPolicy.GetCostForStorageKey(keySize)); err != nil {
+		return 0, err
+	}
 	key, err := readAt(vm, keyPtr, keySize)
 	if err != nil {
 		return 0, err
@@ -54,12 +65,19 @@ func (engine *Engine) chainStorageGet(vm *vm.VM, args ...uint64) (uint64, error)
 	if err != nil {
 		return 0, err
 	}
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForMemory(len(value))); err != nil {
+		return 0, err
+	}
 	byteSize, err := vm.MemWrite(value, valuePtr)
 	return uint64(byteSize), err
 }
 
 func (engine *Engine) chainStorageSizeGet(vm *vm.VM, args ...uint64) (uint64, error) {
 	keyPtr, keySize := int(args[0]), int(args[1])
+	// Burn gas before actually execute
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForStorageRead() + engine.gasPolicy.GetCostForStorageKey(keySize)); err != nil {
+		return 0, err
+	}
 	key, err := readAt(vm, keyPtr, keySize)
 	if err != nil {
 		return 0, err
@@ -68,28 +86,248 @@ func (engine *Engine) chainStorageSizeGet(vm *vm.VM, args ...uint64) (uint64, er
 	return uint64(len(value)), err
 }
 
+func (engine *Engine) chainStorageDelete(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := engine.requireWritable(); err != nil {
+		return 0, err
+	}
+	keyPtr, keySize := int(args[0]), int(args[1])
+	// Burn gas before actually execute
+	err := vm.BurnGas(engine.hostPolicy.GetCostForStorageDelete() + engine.hostPolicy.GetCostForStorageKey(keySize))
+	if err != nil {
+		return 0, err
+	}
+	key, err := readAt(vm, keyPtr, keySize)
+	if err != nil {
+		return 0, err
+	}
+	value, err := engine.account.GetStorage(key)
+	if err != nil || len(value) == 0 {
+		return 0, err
+	}
+	if err := engine.account.DeleteStorage(key); err != nil {
+		return 0, err
+	}
+	engine.refundGas(engine.hostPolicy.GetRefundForStorage(len(value)))
+	return uint64(len(value)), nil
+}
+
+func (engine *Engine) chainStorageIteratorNew(vm *vm.VM, args ...uint64) (uint64, error) {
+	prefixPtr, prefixSize := int(args[0]), int(args[1])
+	if len(engine.iterators) >= constant.MaxStorageIterators {
+		return 0, errors.New("storage iterator limit reached")
+	}
+	// Burn gas before actually execute
+	err := vm.BurnGas(engine.hostPolicy.GetCostForStorageIteration() + engine.hostPolicy.GetCostForStorageIterationBytes(prefixSize))
+	if err != nil {
+		return 0, err
+	}
+	prefix, err := readAt(vm, prefixPtr, prefixSize)
+	if err != nil {
+		return 0, err
+	}
+	engine.iterators = append(engine.iterators, engine.account.NewStorageIterator(prefix))
+	return uint64(len(engine.iterators) - 1), nil
+}
+
+func (engine *Engine) chainStorageIteratorNext(vm *vm.VM, args ...uint64) (uint64, error) {
+	iterator, err := engine.getIterator(args[0])
+	if err != nil {
+		return 0, err
+	}
+	// Burn base gas before stepping, then gas for the loaded key and value
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForStorageIteration()); err != nil {
+		return 0, err
+	}
+	if !iterator.Next() {
+		return 0, iterator.Err()
+	}
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForStorageIterationBytes(len(iterator.Key()) + len(iterator.Value()))); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (engine *Engine) chainStorageIteratorKeyGet(vm *vm.VM, args ...uint64) (uint64, error) {
+	iterator, err := engine.getIterator(args[0])
+	if err != nil {
+		return 0, err
+	}
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForMemory(len(iterator.Key()))); err != nil {
+		return 0, err
+	}
+	byteSize, err := vm.MemWrite(iterator.Key(), int(uint32(args[1])))
+	return uint64(byteSize), err
+}
+
+func (engine *Engine) chainStorageIteratorKeySizeGet(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
+	iterator, err := engine.getIterator(args[0])
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(iterator.Key())), nil
+}
+
+func (engine *Engine) chainStorageIteratorValueGet(vm *vm.VM, args ...uint64) (uint64, error) {
+	iterator, err := engine.getIterator(args[0])
+	if err != nil {
+		return 0, err
+	}
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForMemory(len(iterator.Value()))); err != nil {
+		return 0, err
+	}
+	byteSize, err := vm.MemWrite(iterator.Value(), int(uint32(args[1])))
+	return uint64(byteSize), err
+}
+
+func (engine *Engine) chainStorageIteratorValueSizeGet(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
+	iterator, err := engine.getIterator(args[0])
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(iterator.Value())), nil
+}
+
 func (engine *Engine) chainGetCaller(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	_, err := vm.MemWrite(engine.caller[:], int(args[0]))
 	return 0, err
 }
 
 func (engine *Engine) chainGetCreator(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	creator := engine.account.Creator
 	_, err := vm.MemWrite(creator[:], int(args[0]))
 	return 0, err
 }
 
+// chainGetAdmin writes admin of contract to ptr, it returns 0 if contract has no admin
+func (engine *Engine) chainGetAdmin(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
+	admin := engine.account.GetAdmin()
+	if _, err := vm.MemWrite(admin[:], int(args[0])); err != nil {
+		return 0, err
+	}
+	if admin == crypto.EmptyAddress {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// chainSetAdmin designates admin allowed to upgrade contract, empty address removes admin
+func (engine *Engine) chainSetAdmin(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := engine.requireWritable(); err != nil {
+		return 0, err
+	}
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
+	adminBytes, err := readAt(vm, int(args[0]), crypto.AddressLength)
+	if err != nil {
+		return 0, err
+	}
+	admin := crypto.EmptyAddress
+	if !bytes.Equal(adminBytes, crypto.EmptyAddress[:]) {
+		if admin, err = crypto.AddressFromBytes(adminBytes); err != nil {
+			return 0, err
+		}
+	}
+	engine.account.SetAdmin(admin)
+	return 0, nil
+}
+
 func (engine *Engine) chainPtrArgSizeGet(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	size, err := engine.ptrArgSizeGet(int(args[0]))
 	return uint64(size), err
 }
 
 func (engine *Engine) chainPtrArgSizeSet(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	engine.ptrArgSizeSet(int(args[0]), int(args[1]))
 	return 0, nil
 }
 
 func (engine *Engine) chainMethodBind(vm *vm.VM, args ...uint64) (uint64, error) {
+	return engine.bindMethod(vm, false, false, args...)
+}
+
+func (engine *Engine) chainMethodBindTry(vm *vm.VM, args ...uint64) (uint64, error) {
+	return engine.bindMethod(vm, true, false, args...)
+}
+
+// chainMethodBindStatic binds method which is called in read-only engine,
+// the call fails if invoked contract modifies state
+func (engine *Engine) chainMethodBindStatic(vm *vm.VM, args ...uint64) (uint64, error) {
+	return engine.bindMethod(vm, false, true, args...)
+}
+
+func (engine *Engine) chainCallStatus(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
+	return engine.callStatus, nil
+}
+
+func (engine *Engine) chainReturnData(vm *vm.VM, args ...uint64) (uint64, error) {
+	dataPtr, dataSize := int(args[0]), int(args[1])
+	// Burn gas before actually execute
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForMemory(dataSize)); err != nil {
+		return 0, err
+	}
+	data, err := readAt(vm, dataPtr, dataSize)
+	if err != nil {
+		return 0, err
+	}
+	engine.returnData = data
+	return 0, nil
+}
+
+func (engine *Engine) chainCallReturnDataSizeGet(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
+	return uint64(len(engine.callReturnData)), nil
+}
+
+func (engine *Engine) chainCallReturnDataGet(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForMemory(len(engine.callReturnData))); err != nil {
+		return 0, err
+	}
+	byteSize, err := vm.MemWrite(engine.callReturnData, int(uint32(args[0])))
+	return uint64(byteSize), err
+}
+
+// callPolicy returns gas policy of cross-contract calls, only calls bound by chain_method_bind existed under gas.AlphaPolicy
+func (engine *Engine) callPolicy(try bool, static bool) gas.Policy {
+	if try || static {
+		return engine.hostPolicy
+	}
+	return engine.gasPolicy
+}
+
+func (engine *Engine) bindMethod(vm *vm.VM, try bool, static bool, args ...uint64) (uint64, error) {
+	// Burn gas before actually execute
+	policy := engine.callPolicy(try, static)
+	cost := policy.GetCostForHostCall() + policy.GetCostForMemory(int(args[2])+int(args[4]))
+	if err := vm.BurnGas(cost); err != nil {
+		return 0, err
+	}
 	contractAddrBytes, err := readAt(vm, int(args[0]), crypto.AddressLength)
 	if err != nil {
 		return 0, err
@@ -110,20 +348,30 @@ func (engine *Engine) chainMethodBind(vm *vm.VM, args ...uint64) (uint64, error)
 		return 0, err
 	}
 	aliasMethod := string(aliasMethodBytes[:len(aliasMethodBytes)-1])
-	engine.methodLookup[aliasMethod] = &foreignMethod{contractAddr, invokedMethod}
+	engine.methodLookup[aliasMethod] = &foreignMethod{contractAddr, invokedMethod, try, static}
 	return 0, nil
 }
 
 func (engine *Engine) chainBlockHeight(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	return engine.state.GetBlock().Height, nil
 }
 
 func (engine *Engine) chainBlockTime(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	return uint64(engine.state.GetBlock().Time), nil
 }
 
 func (engine *Engine) chainArgsWrite(vm *vm.VM, args ...uint64) (uint64, error) {
 	bufferPtr, valuePtr, valueSize := int(args[0]), int(args[1]), int(args[2])
+	// Burn gas before actually execute
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	bufferSize, _ := engine.ptrArgSizeGet(bufferPtr)
 	memorySize := 4
 	buf := make([]byte, memorySize)
@@ -145,15 +393,27 @@ func (engine *Engine) chainArgsHash(vm *vm.VM, args ...uint64) (uint64, error) {
 	}
 	memorySize := 4
 	argCnt := bufferSize / memorySize
-	var values [][]byte
+	ptrs := make([]int, argCnt)
+	ptrSizes := make([]int, argCnt)
+	totalSize := 0
 	for i := 0; i < argCnt; i++ {
 		ptrMem, err := readAt(vm, bufferPtr+i*memorySize, memorySize)
-		ptr := int(binary.LittleEndian.Uint32(ptrMem))
-		ptrSize, err := engine.ptrArgSizeGet(ptr)
 		if err != nil {
 			return 0, err
 		}
-		value, err := readAt(vm, ptr, ptrSize)
+		ptrs[i] = int(binary.LittleEndian.Uint32(ptrMem))
+		if ptrSizes[i], err = engine.ptrArgSizeGet(ptrs[i]); err != nil {
+			return 0, err
+		}
+		totalSize += ptrSizes[i]
+	}
+	// Burn gas before actually hashing
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHash(totalSize)); err != nil {
+		return 0, err
+	}
+	var values [][]byte
+	for i := 0; i < argCnt; i++ {
+		value, err := readAt(vm, ptrs[i], ptrSizes[i])
 		if err != nil {
 			return 0, err
 		}
@@ -170,6 +430,10 @@ func (engine *Engine) chainArgsHash(vm *vm.VM, args ...uint64) (uint64, error) {
 
 func (engine *Engine) chainEd25519Verify(vm *vm.VM, args ...uint64) (uint64, error) {
 	addressPtr, hasherPtr, signaturePtr := int(args[0]), int(args[1]), int(args[2])
+	// Burn gas before actually execute
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForEd25519(hashSize)); err != nil {
+		return 0, err
+	}
 	addressBytes, err := readAt(vm, addressPtr, crypto.AddressLength)
 	if err != nil {
 		return 0, err
@@ -178,7 +442,7 @@ func (engine *Engine) chainEd25519Verify(vm *vm.VM, args ...uint64) (uint64, err
 	if err != nil {
 		return 0, err
 	}
-	hasher, err := readAt(vm, hasherPtr, 32)
+	hasher, err := readAt(vm, hasherPtr, hashSize)
 	if err != nil {
 		return 0, err
 	}
@@ -194,6 +458,9 @@ func (engine *Engine) chainEd25519Verify(vm *vm.VM, args ...uint64) (uint64, err
 }
 
 func (engine *Engine) chainGetContractAddress(vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := vm.BurnGas(engine.gasPolicy.GetCostForHostCall()); err != nil {
+		return 0, err
+	}
 	addressPtr := int(args[0])
 	contractAddr := engine.account.GetAddress()
 	vm.MemWrite(contractAddr[:], addressPtr)
@@ -204,12 +471,17 @@ func (engine *Engine) handleInvokeAlias(foreignMethod *foreignMethod, vm *vm.VM,
 	if engine.callDepth+1 > constant.MaxEngineCallDepth {
 		return 0, errors.New("call depth limit reached")
 	}
+	// Burn gas before actually loading foreign contract
+	policy := engine.callPolicy(foreignMethod.try, foreignMethod.static)
+	if err := vm.BurnGas(policy.GetCostForCall()); err != nil {
+		return 0, err
+	}
 
 	foreignAccount, err := engine.state.LoadAccount(foreignMethod.contractAddress)
 	if err != nil {
 		return 0, err
 	}
-	contract, err := foreignAccount.GetContract()
+	contract, err := LoadContract(foreignAccount)
 	if err != nil {
 		return 0, err
 	}
@@ -223,27 +495,26 @@ func (engine *Engine) handleInvokeAlias(foreignMethod *foreignMethod, vm *vm.VM,
 	var values [][]byte
 	var bytes []byte
 	for i, param := range function.Parameters {
-		if param.IsArray {
+		if param.Type == abi.Tuple {
+			bytes, err = engine.readTupleArgument(vm, param, int(args[i]))
+			if err != nil {
+				return 0, err
+			}
+		} else if param.IsPointer() {
 			argPtr := int(args[i])
-			size, _ := engine.ptrArgSizeGet(int(args[i]))
+			size := param.GetMemorySize()
+			if size == 0 {
+				size, _ = engine.ptrArgSizeGet(argPtr)
+			}
 			bytes, err = readAt(vm, argPtr, size)
 			if err != nil {
 				return 0, err
 			}
 		} else {
-			if param.Type.IsPointer() {
-				argPtr := int(args[i])
-				size := param.Type.GetMemorySize()
-				bytes, err = readAt(vm, argPtr, size)
-				if err != nil {
-					return 0, err
-				}
-			} else {
-				bytes = make([]byte, 8)
-				binary.LittleEndian.PutUint64(bytes, args[i])
-				size := param.Type.GetMemorySize()
-				bytes = bytes[:size]
-			}
+			bytes = make([]byte, 8)
+			binary.LittleEndian.PutUint64(bytes, args[i])
+			size := param.Type.GetMemorySize()
+			bytes = bytes[:size]
 		}
 		values = append(values, bytes)
 
@@ -252,14 +523,131 @@ func (engine *Engine) handleInvokeAlias(foreignMethod *foreignMethod, vm *vm.VM,
 	if err != nil {
 		return 0, err
 	}
+	if err := vm.BurnGas(policy.GetCostForMemory(len(methodArgs))); err != nil {
+		return 0, err
+	}
 
 	account, err := engine.state.LoadAccount(foreignMethod.contractAddress)
 	if err != nil {
 		return 0, err
 	}
+	// Changes of child engine are kept only when the call succeeds
+	engine.callReturnData = nil
+	snapshot := engine.state.Snapshot()
+	childEngine := engine.newChildEngine(account)
+	childEngine.static = engine.static || foreignMethod.static
+	childEngine.setStats(engine.callDepth+1, engine.memAggr+vm.MemSize())
+	result, err := childEngine.Ignite(foreignMethod.name, methodArgs)
+	if err != nil {
+		// Changes of failed call are reverted whether the failure is caught or not
+		engine.state.RevertToSnapshot(snapshot)
+		engine.callStatus = CallStatusFailure
+		// Gas is shared with child engine, out of gas cannot be caught
+		if !foreignMethod.try || err == vertex.ErrOutOfGas {
+			return 0, err
+		}
+		return 0, nil
+	}
+	engine.state.DiscardSnapshot(snapshot)
+	engine.refundGas(childEngine.gasRefund)
+	engine.callReturnData = childEngine.returnData
+	for _, event := range childEngine.events {
+		engine.pushEvent(event)
+	}
+	engine.callStatus = CallStatusSuccess
+	return result, nil
+}
+
+// deployHostFunction returns host function which deploys contract of contractSize at contractPtr
+// to address derived from this contract, 32 bytes salt at saltPtr and the contract,
+// then runs init function of contract with RLP encoded args if it has one.
+// Address of deployed contract is written to addressPtr
+func (engine *Engine) deployHostFunction(deriveAddress func(deployer crypto.Address, salt common.Hash, contract []byte) crypto.Address) vm.HostFunction {
+	return func(vm *vm.VM, args ...uint64) (uint64, error) {
+		return engine.chainDeploy(vm, deriveAddress, args...)
+	}
+}
+
+func saltedDeploymentAddress(deployer crypto.Address, salt common.Hash, contract []byte) crypto.Address {
+	return crypto.NewSaltedDeploymentAddress(deployer, salt)
+}
+
+func deterministicDeploymentAddress(deployer crypto.Address, salt common.Hash, contract []byte) crypto.Address {
+	return crypto.NewDeterministicDeploymentAddress(deployer, salt, blake2b.Sum256(contract))
+}
+
+func (engine *Engine) chainDeploy(vm *vm.VM, deriveAddress func(crypto.Address, common.Hash, []byte) crypto.Address, args ...uint64) (uint64, error) {
+	contractPtr, contractSize, saltPtr := int(args[0]), int(args[1]), int(args[2])
+	argsPtr, argsSize, addressPtr := int(args[3]), int(args[4]), int(args[5])
+	if err := engine.requireWritable(); err != nil {
+		return 0, err
+	}
+	// Burn gas before actually deploying contract
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForContract(contractSize)); err != nil {
+		return 0, err
+	}
+	contractBytes, err := readAt(vm, contractPtr, contractSize)
+	if err != nil {
+		return 0, err
+	}
+	salt, err := readAt(vm, saltPtr, hashSize)
+	if err != nil {
+		return 0, err
+	}
+	initArgs, err := readAt(vm, argsPtr, argsSize)
+	if err != nil {
+		return 0, err
+	}
+	address := deriveAddress(engine.account.GetAddress(), common.BytesToHash(salt), contractBytes)
+	if err := engine.deployContract(vm, address, contractBytes, initArgs); err != nil {
+		return 0, err
+	}
+	_, err = vm.MemWrite(address[:], addressPtr)
+	return 0, err
+}
+
+// deployContract creates contract account created by this contract at address and runs its init function
+func (engine *Engine) deployContract(vm *vm.VM, address crypto.Address, contractBytes, initArgs []byte) error {
+	contract, err := abi.DecodeContract(contractBytes)
+	if err != nil {
+		return err
+	}
+	existing, err := engine.state.LoadAccount(address)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("account %s already exists", address.String())
+	}
+	account, err := engine.state.CreateAccount(engine.account.GetAddress(), address, contractBytes)
+	if err != nil {
+		return err
+	}
+
+	function, err := contract.Header.GetFunction(InitFunctionName)
+	if err != nil {
+		return nil
+	}
+	if engine.callDepth+1 > constant.MaxEngineCallDepth {
+		return errors.New("call depth limit reached")
+	}
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForCall() + engine.hostPolicy.GetCostForMemory(len(initArgs))); err != nil {
+		return err
+	}
+	// Init without args can be given as empty args
+	if len(initArgs) == 0 {
+		initArgs = rlp.EmptyList
+	}
 	childEngine := engine.newChildEngine(account)
 	childEngine.setStats(engine.callDepth+1, engine.memAggr+vm.MemSize())
-	return childEngine.Ignite(foreignMethod.name, methodArgs)
+	if _, err := childEngine.Ignite(function.Name, initArgs); err != nil {
+		return err
+	}
+	engine.refundGas(childEngine.gasRefund)
+	for _, event := range childEngine.events {
+		engine.pushEvent(event)
+	}
+	return nil
 }
 
 // GetFunction get host function for WebAssembly
@@ -273,12 +661,46 @@ func (engine *Engine) GetFunction(module, name string) vm.HostFunction {
 			return engine.chainStorageGet
 		case "chain_storage_size_get":
 			return engine.chainStorageSizeGet
+		case "chain_storage_delete":
+			return engine.chainStorageDelete
+		case "chain_storage_iterator_new":
+			return engine.chainStorageIteratorNew
+		case "chain_storage_iterator_next":
+			return engine.chainStorageIteratorNext
+		case "chain_storage_iterator_key_get":
+			return engine.chainStorageIteratorKeyGet
+		case "chain_storage_iterator_key_size_get":
+			return engine.chainStorageIteratorKeySizeGet
+		case "chain_storage_iterator_value_get":
+			return engine.chainStorageIteratorValueGet
+		case "chain_storage_iterator_value_size_get":
+			return engine.chainStorageIteratorValueSizeGet
 		case "chain_get_caller":
 			return engine.chainGetCaller
 		case "chain_get_creator":
 			return engine.chainGetCreator
+		case "chain_get_admin":
+			return engine.chainGetAdmin
+		case "chain_set_admin":
+			return engine.chainSetAdmin
 		case "chain_method_bind":
 			return engine.chainMethodBind
+		case "chain_deploy":
+			return engine.deployHostFunction(saltedDeploymentAddress)
+		case "chain_deploy_deterministic":
+			return engine.deployHostFunction(deterministicDeploymentAddress)
+		case "chain_method_bind_try":
+			return engine.chainMethodBindTry
+		case "chain_method_bind_static":
+			return engine.chainMethodBindStatic
+		case "chain_call_status":
+			return engine.chainCallStatus
+		case "chain_return_data":
+			return engine.chainReturnData
+		case "chain_call_return_data_size_get":
+			return engine.chainCallReturnDataSizeGet
+		case "chain_call_return_data_get":
+			return engine.chainCallReturnDataGet
 		case "chain_arg_size_get":
 			return engine.chainPtrArgSizeGet
 		case "chain_arg_size_set":
@@ -295,8 +717,20 @@ func (engine *Engine) GetFunction(module, name string) vm.HostFunction {
 			return engine.chainEd25519Verify
 		case "chain_get_contract_address":
 			return engine.chainGetContractAddress
+		case "chain_blake2b_256":
+			return engine.hashHostFunction(blake2b256)
+		case "chain_sha256":
+			return engine.hashHostFunction(sha256Digest)
+		case "chain_keccak256":
+			return engine.hashHostFunction(keccak256)
+		case "chain_secp256k1_recover":
+			return engine.chainSecp256k1Recover
+		case "chain_secp256k1_verify":
+			return engine.chainSecp256k1Verify
+		case "chain_ed25519_verify_message":
+			return engine.chainEd25519VerifyMessage
 		default:
-			contract, _ := engine.account.GetContract()
+			contract, _ := LoadContract(engine.account)
 			if event, err := contract.Header.GetEvent(name); err == nil {
 				return func(vm *vm.VM, args ...uint64) (uint64, error) {
 					return engine.handleEmitEvent(event, vm, args...)
@@ -1,13 +1,24 @@
 package engine
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"testing"
 
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/constant"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/db"
 	"github.com/QuoineFinancial/liquid-chain/gas"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/vertexdlt/vertexvm/vm"
 	vertex "github.com/vertexdlt/vertexvm/vm"
+	"golang.org/x/crypto/blake2b"
 )
 
 type testcase struct {
@@ -57,3 +68,582 @@ func TestAdapterGetFunction(t *testing.T) {
 		}
 	}
 }
+
+// newHostTestEngine returns an engine of a contract account and a VM to call its host functions
+func newHostTestEngine(gasPolicy gas.Policy, gasLimit uint64) (*Engine, *vm.VM) {
+	state := storage.NewStateStorage(db.NewMemoryDB())
+	if err := state.LoadState(&crypto.Block{Height: 1}); err != nil {
+		panic(err)
+	}
+	address, _ := crypto.AddressFromString("LADSUJQLIKT4WBBLGLJ6Q36DEBJ6KFBQIIABD6B3ZWF7NIE4RIZURI53")
+	account, err := state.CreateAccount(crypto.EmptyAddress, address, nil)
+	if err != nil {
+		panic(err)
+	}
+	engine := NewEngine(state, account, crypto.EmptyAddress, gasPolicy, gasLimit)
+
+	code, err := ioutil.ReadFile("testdata/exit.wasm")
+	if err != nil {
+		panic(err)
+	}
+	vm, err := vertex.NewVM(code, gasPolicy, engine.gas, engine)
+	if err != nil {
+		panic(err)
+	}
+	return engine, vm
+}
+
+func TestChainStorageDelete(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 10000)
+	key, value := []byte("balance"), bytes.Repeat([]byte{1}, 100)
+	if err := engine.account.SetStorage(key, value); err != nil {
+		t.Fatal(err)
+	}
+	vm.MemWrite(key, 0)
+	engine.gas.Used = 200
+
+	size, err := engine.chainStorageDelete(vm, 0, uint64(len(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != uint64(len(value)) {
+		t.Errorf("Expect deleted size %v, got %v", len(value), size)
+	}
+	if got, _ := engine.account.GetStorage(key); len(got) != 0 {
+		t.Errorf("Expect key to be deleted, got %v", got)
+	}
+	// Refund of 50 for 100 freed bytes
+	deleteCost := gas.GasStorageDelete + gas.GasStorageKeyByte*uint64(len(key))
+	if got := engine.GetGasUsed(); got != 200+deleteCost-50 {
+		t.Errorf("Expect gas used %v, got %v", 200+deleteCost-50, got)
+	}
+
+	// Deleting missing key costs gas without refund
+	size, err = engine.chainStorageDelete(vm, 0, uint64(len(key)))
+	if err != nil || size != 0 {
+		t.Errorf("Expect deleted size 0 without error, got %v, %v", size, err)
+	}
+	if got := engine.GetGasUsed(); got != 200+2*deleteCost-50 {
+		t.Errorf("Expect gas used %v, got %v", 200+2*deleteCost-50, got)
+	}
+
+	// Refund is capped at half of used gas
+	engine.refundGas(1000)
+	if got := engine.GetGasUsed(); got != engine.gas.Used-engine.gas.Used/2 {
+		t.Errorf("Expect gas used %v, got %v", engine.gas.Used-engine.gas.Used/2, got)
+	}
+
+	engine.gas.Used = engine.gas.Limit
+	if _, err := engine.chainStorageDelete(vm, 0, uint64(len(key))); err != vertex.ErrOutOfGas {
+		t.Errorf("Expect error %v, got %v", vertex.ErrOutOfGas, err)
+	}
+}
+
+func TestChainStorageIterator(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 10000)
+	for _, key := range []string{"order:2", "order:1", "other", "order:3", "orde"} {
+		if err := engine.account.SetStorage([]byte(key), []byte("value of "+key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prefix := []byte("order:")
+	vm.MemWrite(prefix, 0)
+	engine.gas.Used = 0
+
+	id, err := engine.chainStorageIteratorNew(vm, 0, uint64(len(prefix)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedGas := gas.GasStorageIteration + uint64(len(prefix))
+	for _, key := range []string{"order:1", "order:2", "order:3"} {
+		if ok, err := engine.chainStorageIteratorNext(vm, id); ok != 1 || err != nil {
+			t.Fatalf("Expect next key %s, got %v, %v", key, ok, err)
+		}
+		value := "value of " + key
+		expectedGas += gas.GasStorageIteration + uint64(len(key)+len(value))
+		expectedGas += 2*gas.GasHostCall + gas.GasMemory*uint64(len(key)+len(value))
+
+		keySize, _ := engine.chainStorageIteratorKeySizeGet(vm, id)
+		valueSize, _ := engine.chainStorageIteratorValueSizeGet(vm, id)
+		if keySize != uint64(len(key)) || valueSize != uint64(len(value)) {
+			t.Errorf("Expect sizes %v, %v, got %v, %v", len(key), len(value), keySize, valueSize)
+		}
+		engine.chainStorageIteratorKeyGet(vm, id, 100)
+		engine.chainStorageIteratorValueGet(vm, id, 200)
+		gotKey, _ := readAt(vm, 100, int(keySize))
+		gotValue, _ := readAt(vm, 200, int(valueSize))
+		if string(gotKey) != key || string(gotValue) != value {
+			t.Errorf("Expect %s = %s, got %s = %s", key, value, gotKey, gotValue)
+		}
+	}
+	if ok, err := engine.chainStorageIteratorNext(vm, id); ok != 0 || err != nil {
+		t.Errorf("Expect end of iteration, got %v, %v", ok, err)
+	}
+	// The last step finds no key but still burns base gas
+	expectedGas += gas.GasStorageIteration
+	if engine.gas.Used != expectedGas {
+		t.Errorf("Expect gas used %v, got %v", expectedGas, engine.gas.Used)
+	}
+
+	if _, err := engine.chainStorageIteratorNext(vm, id+1); err == nil || err.Error() != "storage iterator not found" {
+		t.Errorf("Expect storage iterator not found, got %v", err)
+	}
+
+	// Stepping without gas for the base cost does not load the next key
+	id, _ = engine.chainStorageIteratorNew(vm, 0, uint64(len(prefix)))
+	engine.gas.Used = engine.gas.Limit - gas.GasStorageIteration + 1
+	if _, err := engine.chainStorageIteratorNext(vm, id); err != vertex.ErrOutOfGas {
+		t.Errorf("Expect error %v, got %v", vertex.ErrOutOfGas, err)
+	}
+	if key := engine.iterators[id].Key(); key != nil {
+		t.Errorf("Expect iterator not stepped, got key %s", key)
+	}
+
+	engine.gas.Used = 0
+	for len(engine.iterators) < constant.MaxStorageIterators {
+		if _, err := engine.chainStorageIteratorNew(vm, 0, uint64(len(prefix))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := engine.chainStorageIteratorNew(vm, 0, uint64(len(prefix))); err == nil || err.Error() != "storage iterator limit reached" {
+		t.Errorf("Expect storage iterator limit reached, got %v", err)
+	}
+}
+
+func TestChainHash(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 10000)
+	tests := []struct {
+		name     string
+		function string
+		data     string
+		want     string
+	}{
+		{"blake2b-256", "chain_blake2b_256", "abc", "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		{"sha256", "chain_sha256", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"keccak256", "chain_keccak256", "", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm.MemWrite([]byte(tt.data), 0)
+			engine.gas.Used = 0
+			if _, err := engine.GetFunction("env", tt.function)(vm, 0, uint64(len(tt.data)), 100); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := readAt(vm, 100, 32)
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("Expect hash %s, got %x", tt.want, got)
+			}
+			if want := gas.GasHash + gas.GasHashWord*uint64((len(tt.data)+31)/32); engine.gas.Used != want {
+				t.Errorf("Expect gas used %v, got %v", want, engine.gas.Used)
+			}
+		})
+	}
+}
+
+func TestChainSecp256k1(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 100000)
+	privateKey, _ := btcec.NewPrivateKey(btcec.S256())
+	hash := bytes.Repeat([]byte{7}, 32)
+	compact, _ := btcec.SignCompact(btcec.S256(), privateKey, hash, false)
+	// Ethereum signature r || s || v from compact 27 + v || r || s
+	signature := append(append([]byte{}, compact[1:]...), compact[0])
+	vm.MemWrite(hash, 0)
+	vm.MemWrite(signature, 100)
+
+	t.Run("recover", func(t *testing.T) {
+		if ok, err := engine.chainSecp256k1Recover(vm, 0, 100, 200); ok != 1 || err != nil {
+			t.Fatalf("Expect public key recovered, got %v, %v", ok, err)
+		}
+		got, _ := readAt(vm, 200, 65)
+		if want := privateKey.PubKey().SerializeUncompressed(); !bytes.Equal(got, want) {
+			t.Errorf("Expect public key %x, got %x", want, got)
+		}
+
+		vm.MemWrite([]byte{5}, 100+64)
+		if ok, err := engine.chainSecp256k1Recover(vm, 0, 100, 200); ok != 0 || err != nil {
+			t.Errorf("Expect invalid recovery id, got %v, %v", ok, err)
+		}
+		vm.MemWrite(signature, 100)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		publicKey := privateKey.PubKey().SerializeCompressed()
+		vm.MemWrite(publicKey, 300)
+		if ok, err := engine.chainSecp256k1Verify(vm, 300, uint64(len(publicKey)), 0, 32, 100, 64); ok != 1 || err != nil {
+			t.Errorf("Expect valid signature, got %v, %v", ok, err)
+		}
+		vm.MemWrite(bytes.Repeat([]byte{8}, 32), 0)
+		if ok, err := engine.chainSecp256k1Verify(vm, 300, uint64(len(publicKey)), 0, 32, 100, 64); ok != 0 || err != nil {
+			t.Errorf("Expect invalid signature, got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("verify invalid sizes", func(t *testing.T) {
+		tests := []struct {
+			args []uint64
+			want string
+		}{
+			{[]uint64{300, 64, 0, 32, 100, 64}, "invalid secp256k1 public key size 64"},
+			{[]uint64{300, 1 << 30, 0, 32, 100, 64}, "invalid secp256k1 public key size 1073741824"},
+			{[]uint64{300, 33, 0, 31, 100, 64}, "invalid secp256k1 hash size 31"},
+			{[]uint64{300, 65, 0, 32, 100, 65}, "invalid secp256k1 signature size 65"},
+		}
+		for _, tt := range tests {
+			if _, err := engine.chainSecp256k1Verify(vm, tt.args...); err == nil || err.Error() != tt.want {
+				t.Errorf("Expect error %s, got %v", tt.want, err)
+			}
+		}
+	})
+
+	engine.gas.Used = engine.gas.Limit - 1
+	if _, err := engine.chainSecp256k1Recover(vm, 0, 100, 200); err != vertex.ErrOutOfGas {
+		t.Errorf("Expect error %v, got %v", vertex.ErrOutOfGas, err)
+	}
+}
+
+func TestChainEd25519VerifyMessage(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 100000)
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	message := bytes.Repeat([]byte("bridge header "), 20)
+	vm.MemWrite(publicKey, 0)
+	vm.MemWrite(message, 100)
+	vm.MemWrite(ed25519.Sign(privateKey, message), 1000)
+	engine.gas.Used = 0
+
+	if ok, err := engine.chainEd25519VerifyMessage(vm, 0, 100, uint64(len(message)), 1000); ok != 1 || err != nil {
+		t.Errorf("Expect valid signature, got %v, %v", ok, err)
+	}
+	if want := gas.GasEd25519 + gas.GasHashWord*uint64((len(message)+31)/32); engine.gas.Used != want {
+		t.Errorf("Expect gas used %v, got %v", want, engine.gas.Used)
+	}
+	if ok, err := engine.chainEd25519VerifyMessage(vm, 0, 100, uint64(len(message)-1), 1000); ok != 0 || err != nil {
+		t.Errorf("Expect invalid signature, got %v, %v", ok, err)
+	}
+}
+
+func TestChainAdmin(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.FreePolicy{}, 10000)
+	admin, _ := crypto.AddressFromString("LCHILMXMODD5DMDMPKVSD5MUODDQMBRU5GZVLGXEFBPG36HV4CLSYM7O")
+
+	if ok, err := engine.chainGetAdmin(vm, 0); ok != 0 || err != nil {
+		t.Errorf("Expect no admin, got %v, %v", ok, err)
+	}
+	vm.MemWrite(admin[:], 100)
+	if _, err := engine.chainSetAdmin(vm, 100); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := engine.chainGetAdmin(vm, 0); ok != 1 || err != nil {
+		t.Errorf("Expect admin, got %v, %v", ok, err)
+	}
+	if written, _ := readAt(vm, 0, crypto.AddressLength); !bytes.Equal(written, admin[:]) || !engine.account.CanUpgrade(admin) {
+		t.Errorf("Expect admin %v, got %v", admin[:], written)
+	}
+
+	vm.MemWrite(crypto.EmptyAddress[:], 100)
+	if _, err := engine.chainSetAdmin(vm, 100); err != nil || engine.account.CanUpgrade(admin) {
+		t.Errorf("Expect admin removed, got %v", err)
+	}
+	vm.MemWrite([]byte{1}, 100)
+	if _, err := engine.chainSetAdmin(vm, 100); err == nil {
+		t.Errorf("Expect error for invalid admin address")
+	}
+}
+
+func TestHostFunctionGas(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 10000)
+	key, value := []byte("key"), bytes.Repeat([]byte{1}, 100)
+	if err := engine.account.SetStorage(key, value); err != nil {
+		t.Fatal(err)
+	}
+	vm.MemWrite(key, 0)
+
+	tests := []struct {
+		name    string
+		call    func() (uint64, error)
+		gasUsed uint64
+	}{{
+		name:    "chain_storage_get",
+		call:    func() (uint64, error) { return engine.chainStorageGet(vm, 0, uint64(len(key)), 100) },
+		gasUsed: gas.GasStorageRead + gas.GasStorageKeyByte*uint64(len(key)) + gas.GasMemory*uint64(len(value)),
+	}, {
+		name:    "chain_storage_size_get",
+		call:    func() (uint64, error) { return engine.chainStorageSizeGet(vm, 0, uint64(len(key))) },
+		gasUsed: gas.GasStorageRead + gas.GasStorageKeyByte*uint64(len(key)),
+	}, {
+		name: "chain_storage_set",
+		call: func() (uint64, error) {
+			return engine.chainStorageSet(vm, 0, uint64(len(key)), 100, uint64(len(value)))
+		},
+		gasUsed: gas.GasStorageKeyByte*uint64(len(key)) + uint64(len(value)),
+	}, {
+		name:    "chain_get_contract_address",
+		call:    func() (uint64, error) { return engine.chainGetContractAddress(vm, 300) },
+		gasUsed: gas.GasHostCall,
+	}, {
+		name:    "chain_ed25519_verify",
+		call:    func() (uint64, error) { return engine.chainEd25519Verify(vm, 300, 0, 400) },
+		gasUsed: gas.GasEd25519 + gas.GasHashWord,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine.gas.Used = 0
+			if _, err := tt.call(); err != nil {
+				t.Fatal(err)
+			}
+			if engine.gas.Used != tt.gasUsed {
+				t.Errorf("Expect gas used %v, got %v", tt.gasUsed, engine.gas.Used)
+			}
+
+			// Gas is burnt before the work, so nothing is written when out of gas
+			engine.gas.Used = engine.gas.Limit
+			if _, err := tt.call(); err != vertex.ErrOutOfGas {
+				t.Errorf("Expect error %v, got %v", vertex.ErrOutOfGas, err)
+			}
+		})
+	}
+}
+
+func TestAlphaPolicyHostFunctionGas(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.AlphaPolicy{}, 10000)
+	key := []byte("key")
+	vm.MemWrite(key, 0)
+	engine.gas.Used = 0
+
+	// Host functions which existed before BetaPolicy are free until chain switches to it
+	engine.chainStorageGet(vm, 0, uint64(len(key)), 100)
+	engine.chainGetContractAddress(vm, 300)
+	engine.chainEd25519Verify(vm, 300, 0, 400)
+	if engine.gas.Used != 0 {
+		t.Errorf("Expect no gas used, got %v", engine.gas.Used)
+	}
+
+	// Host functions added along with BetaPolicy are charged from the start
+	if _, err := engine.chainStorageDelete(vm, 0, uint64(len(key))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.hashHostFunction(sha256Digest)(vm, 0, uint64(len(key)), 200); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.chainCallStatus(vm); err != nil {
+		t.Fatal(err)
+	}
+	gasUsed := gas.GasStorageDelete + uint64(len(key))*gas.GasStorageKeyByte + gas.GasHash + gas.GasHashWord + gas.GasHostCall
+	if engine.gas.Used != gasUsed {
+		t.Errorf("Expect gas used %v, got %v", gasUsed, engine.gas.Used)
+	}
+}
+
+func TestHandleInvokeAliasRevert(t *testing.T) {
+	calleeAddress, _ := crypto.AddressFromString("LCR57ROUHIQ2AV4D3E3D7ZBTR6YXMKZQWTI4KSHSWCUCRXBKNJKKBCNY")
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 100000)
+	contractBytes, _ := rlp.EncodeToBytes(loadContract("testdata/revert-abi.json", "testdata/revert.wasm"))
+	callee, err := engine.state.CreateAccount(crypto.EmptyAddress, calleeAddress, contractBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := &foreignMethod{calleeAddress, "write", false, false}
+	fail := &foreignMethod{calleeAddress, "fail", false, false}
+	tryFail := &foreignMethod{calleeAddress, "fail", true, false}
+
+	if result, err := engine.handleInvokeAlias(write, vm); result != 1 || err != nil {
+		t.Fatalf("Expect write succeeded, got %v, %v", result, err)
+	}
+	if len(engine.GetEvents()) != 1 || engine.callStatus != CallStatusSuccess {
+		t.Fatalf("Expect 1 event and success status, got %v, %v", len(engine.GetEvents()), engine.callStatus)
+	}
+
+	if _, err := engine.handleInvokeAlias(fail, vm); err != vertex.ErrUnreachable {
+		t.Errorf("Expect error %v, got %v", vertex.ErrUnreachable, err)
+	}
+
+	engine.refundGas(10)
+	if result, err := engine.handleInvokeAlias(tryFail, vm); result != 0 || err != nil {
+		t.Errorf("Expect failure caught, got %v, %v", result, err)
+	}
+	if engine.callStatus != CallStatusFailure || engine.gasRefund != 10 {
+		t.Errorf("Expect failure status and refund kept, got %v, %v", engine.callStatus, engine.gasRefund)
+	}
+	if len(engine.GetEvents()) != 1 {
+		t.Errorf("Expect events of failed call dropped, got %v events", len(engine.GetEvents()))
+	}
+
+	// Storage written before the failure is reverted
+	if value, _ := callee.GetStorage([]byte("key")); string(value) != "value" {
+		t.Errorf("Expect storage kept, got %s", value)
+	}
+	if value, _ := callee.GetStorage([]byte("bad")); value != nil {
+		t.Errorf("Expect storage reverted, got %s", value)
+	}
+
+	// Refund of storage deleted by a failed call is dropped along with the deletion
+	tryClear := &foreignMethod{calleeAddress, "clear", true, false}
+	if result, err := engine.handleInvokeAlias(tryClear, vm); result != 0 || err != nil {
+		t.Errorf("Expect failure caught, got %v, %v", result, err)
+	}
+	if engine.gasRefund != 10 {
+		t.Errorf("Expect refund of failed call dropped, got %v", engine.gasRefund)
+	}
+	if value, _ := callee.GetStorage([]byte("key")); string(value) != "value" {
+		t.Errorf("Expect deletion reverted, got %s", value)
+	}
+
+	engine.gas.Used = engine.gas.Limit - gas.GasCall
+	if _, err := engine.handleInvokeAlias(tryFail, vm); err != vertex.ErrOutOfGas {
+		t.Errorf("Expect error %v, got %v", vertex.ErrOutOfGas, err)
+	}
+
+	// Try call running out of gas at any point is reverted, including after its storage write
+	for remaining := gas.GasCall; ; remaining++ {
+		engine.gas.Used = engine.gas.Limit - remaining
+		_, err := engine.handleInvokeAlias(tryFail, vm)
+		if err != vertex.ErrOutOfGas {
+			if err != nil || remaining == gas.GasCall {
+				t.Errorf("Expect out of gas before the failure is caught, got %v", err)
+			}
+			break
+		}
+		if value, _ := callee.GetStorage([]byte("bad")); value != nil {
+			t.Fatalf("Expect storage reverted with %v gas, got %s", remaining, value)
+		}
+	}
+}
+
+func TestStaticCall(t *testing.T) {
+	calleeAddress, _ := crypto.AddressFromString("LCR57ROUHIQ2AV4D3E3D7ZBTR6YXMKZQWTI4KSHSWCUCRXBKNJKKBCNY")
+	valuesAddress, _ := crypto.AddressFromString("LDH4MEPOJX3EGN3BLBTLEYXVHYCN3AVA7IOE772F3XGI6VNZHAP6GX5R")
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 100000)
+	contractBytes, _ := rlp.EncodeToBytes(loadContract("testdata/revert-abi.json", "testdata/revert.wasm"))
+	callee, _ := engine.state.CreateAccount(crypto.EmptyAddress, calleeAddress, contractBytes)
+	contractBytes, _ = rlp.EncodeToBytes(loadContract("testdata/return-data-abi.json", "testdata/return-data.wasm"))
+	engine.state.CreateAccount(crypto.EmptyAddress, valuesAddress, contractBytes)
+
+	if result, err := engine.handleInvokeAlias(&foreignMethod{valuesAddress, "values", false, true}, vm); result != 3 || err != nil {
+		t.Fatalf("Expect read-only call succeeded, got %v, %v", result, err)
+	}
+	if _, err := engine.handleInvokeAlias(&foreignMethod{calleeAddress, "write", false, true}, vm); err != ErrStaticCall {
+		t.Errorf("Expect error %v, got %v", ErrStaticCall, err)
+	}
+	if result, err := engine.handleInvokeAlias(&foreignMethod{calleeAddress, "write", true, true}, vm); result != 0 || err != nil || engine.callStatus != CallStatusFailure {
+		t.Errorf("Expect failure caught, got %v, %v, status %v", result, err, engine.callStatus)
+	}
+	if value, _ := callee.GetStorage([]byte("key")); value != nil {
+		t.Errorf("Expect no storage written, got %s", value)
+	}
+	if len(engine.GetEvents()) != 0 {
+		t.Errorf("Expect no events, got %v events", len(engine.GetEvents()))
+	}
+
+	// Static engine is read-only for nested calls too
+	engine.static = true
+	if _, err := engine.handleInvokeAlias(&foreignMethod{calleeAddress, "write", false, false}, vm); err != ErrStaticCall {
+		t.Errorf("Expect error %v, got %v", ErrStaticCall, err)
+	}
+	writes := map[string]func() (uint64, error){
+		"storage set":    func() (uint64, error) { return engine.chainStorageSet(vm, 0, 3, 3, 5) },
+		"storage delete": func() (uint64, error) { return engine.chainStorageDelete(vm, 0, 3) },
+		"set admin":      func() (uint64, error) { return engine.chainSetAdmin(vm, 0) },
+		"event":          func() (uint64, error) { return engine.handleEmitEvent(&abi.Event{Name: "stored"}, vm) },
+		"deploy": func() (uint64, error) {
+			return engine.deployHostFunction(saltedDeploymentAddress)(vm, 0, 0, 0, 0, 0, 0)
+		},
+	}
+	for name, write := range writes {
+		if _, err := write(); err != ErrStaticCall {
+			t.Errorf("Expect %s failed with %v, got %v", name, ErrStaticCall, err)
+		}
+	}
+
+	staticEngine := NewStaticEngine(engine.state, callee, crypto.EmptyAddress, &gas.FreePolicy{}, 0)
+	args, _ := abi.EncodeFromString([]*abi.Parameter{}, []string{})
+	if _, err := staticEngine.Ignite("write", args); err != ErrStaticCall {
+		t.Errorf("Expect error %v, got %v", ErrStaticCall, err)
+	}
+}
+
+func TestCallReturnData(t *testing.T) {
+	calleeAddress, _ := crypto.AddressFromString("LCR57ROUHIQ2AV4D3E3D7ZBTR6YXMKZQWTI4KSHSWCUCRXBKNJKKBCNY")
+	revertAddress, _ := crypto.AddressFromString("LDH4MEPOJX3EGN3BLBTLEYXVHYCN3AVA7IOE772F3XGI6VNZHAP6GX5R")
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 100000)
+	contractBytes, _ := rlp.EncodeToBytes(loadContract("testdata/return-data-abi.json", "testdata/return-data.wasm"))
+	callee, _ := engine.state.CreateAccount(crypto.EmptyAddress, calleeAddress, contractBytes)
+	contractBytes, _ = rlp.EncodeToBytes(loadContract("testdata/revert-abi.json", "testdata/revert.wasm"))
+	engine.state.CreateAccount(crypto.EmptyAddress, revertAddress, contractBytes)
+	want := []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}
+
+	if result, err := engine.handleInvokeAlias(&foreignMethod{calleeAddress, "values", false, false}, vm); result != 3 || err != nil {
+		t.Fatalf("Expect call succeeded, got %v, %v", result, err)
+	}
+	if size, _ := engine.chainCallReturnDataSizeGet(vm); size != uint64(len(want)) {
+		t.Errorf("Expect return data size %v, got %v", len(want), size)
+	}
+	engine.chainCallReturnDataGet(vm, 100)
+	if got, _ := readAt(vm, 100, len(want)); !bytes.Equal(got, want) {
+		t.Errorf("Expect return data %v, got %v", want, got)
+	}
+	if len(engine.GetReturnData()) != 0 {
+		t.Errorf("Expect no return data of caller, got %v", engine.GetReturnData())
+	}
+
+	// Return data is cleared by a failed call
+	engine.handleInvokeAlias(&foreignMethod{revertAddress, "fail", true, false}, vm)
+	if size, _ := engine.chainCallReturnDataSizeGet(vm); size != 0 {
+		t.Errorf("Expect return data cleared, got size %v", size)
+	}
+
+	calleeEngine := NewEngine(engine.state, callee, crypto.EmptyAddress, &gas.FreePolicy{}, 0)
+	args, _ := abi.EncodeFromString([]*abi.Parameter{}, []string{})
+	if _, err := calleeEngine.Ignite("values", args); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(calleeEngine.GetReturnData(), want) {
+		t.Errorf("Expect return data %v, got %v", want, calleeEngine.GetReturnData())
+	}
+}
+
+func TestChainDeploy(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.BetaPolicy{}, 100000)
+	contractBytes, _ := rlp.EncodeToBytes(loadContract("testdata/init-abi.json", "testdata/init.wasm"))
+	salt := blake2b.Sum256([]byte("pair"))
+	vm.MemWrite(salt[:], 100)
+	vm.MemWrite(contractBytes, 1000)
+	engine.gas.Used = 0
+
+	if _, err := engine.deployHostFunction(saltedDeploymentAddress)(vm, 1000, uint64(len(contractBytes)), 100, 0, 0, 200); err != nil {
+		t.Fatal(err)
+	}
+	want := crypto.NewSaltedDeploymentAddress(engine.account.GetAddress(), salt)
+	if address, _ := readAt(vm, 200, crypto.AddressLength); !bytes.Equal(address, want[:]) {
+		t.Errorf("Expect deployed address %v, got %v", want[:], address)
+	}
+	deployed, _ := engine.state.LoadAccount(want)
+	if deployed == nil || deployed.GetCreator() != engine.account.GetAddress() {
+		t.Fatalf("Expect contract account created by deployer, got %v", deployed)
+	}
+	if value, _ := deployed.GetStorage([]byte("init")); !bytes.Equal(value, []byte("done")) {
+		t.Errorf("Expect init run, got storage %s", value)
+	}
+	if minimum := (&gas.BetaPolicy{}).GetCostForContract(len(contractBytes)); engine.gas.Used < minimum {
+		t.Errorf("Expect gas used at least %v, got %v", minimum, engine.gas.Used)
+	}
+
+	if _, err := engine.deployHostFunction(saltedDeploymentAddress)(vm, 1000, uint64(len(contractBytes)), 100, 0, 0, 200); err == nil {
+		t.Errorf("Expect error for deploying to existing address")
+	}
+
+	contractBytes, _ = rlp.EncodeToBytes(loadContract("testdata/revert-abi.json", "testdata/revert.wasm"))
+	vm.MemWrite(contractBytes, 1000)
+	vm.MemWrite([]byte{1}, 100)
+	if _, err := engine.deployHostFunction(saltedDeploymentAddress)(vm, 1000, uint64(len(contractBytes)), 100, 0, 0, 200); err != nil {
+		t.Errorf("Expect contract without init deployed, got %v", err)
+	}
+
+	salt = blake2b.Sum256([]byte("deterministic"))
+	vm.MemWrite(salt[:], 100)
+	deterministic := crypto.NewDeterministicDeploymentAddress(engine.account.GetAddress(), salt, blake2b.Sum256(contractBytes))
+	if _, err := engine.deployHostFunction(deterministicDeploymentAddress)(vm, 1000, uint64(len(contractBytes)), 100, 0, 0, 200); err != nil {
+		t.Fatal(err)
+	}
+	if address, _ := readAt(vm, 200, crypto.AddressLength); !bytes.Equal(address, deterministic[:]) {
+		t.Errorf("Expect deterministic address %v, got %v", deterministic[:], address)
+	}
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+	"github.com/vertexdlt/vertexvm/wasm"
+)
+
+// contractCacheSize is number of decoded contracts kept in memory
+const contractCacheSize = 256
+
+// contracts is shared by all engines, including the ones of API calls
+var contracts = newContractCache(contractCacheSize)
+
+// contractCache is a least recently used cache of decoded contracts by contract hash, with their parsed
+// wasm modules once engines run them. Cached contracts and modules are shared between engines and must not be modified
+type contractCache struct {
+	mutex    sync.Mutex
+	capacity int
+	items    map[common.Hash]*list.Element
+	order    *list.List
+}
+
+type contractCacheItem struct {
+	hash     common.Hash
+	contract *abi.Contract
+	module   *wasm.Module
+}
+
+func newContractCache(capacity int) *contractCache {
+	return &contractCache{
+		capacity: capacity,
+		items:    make(map[common.Hash]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (cache *contractCache) get(hash common.Hash) (*abi.Contract, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	element, ok := cache.items[hash]
+	if !ok {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*contractCacheItem).contract, true
+}
+
+func (cache *contractCache) add(hash common.Hash, contract *abi.Contract) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, ok := cache.items[hash]; ok {
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.items[hash] = cache.order.PushFront(&contractCacheItem{hash: hash, contract: contract})
+	if cache.order.Len() > cache.capacity {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.items, oldest.Value.(*contractCacheItem).hash)
+	}
+}
+
+func (cache *contractCache) getModule(hash common.Hash) (*wasm.Module, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	element, ok := cache.items[hash]
+	if !ok || element.Value.(*contractCacheItem).module == nil {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*contractCacheItem).module, true
+}
+
+// setModule keeps module with contract of hash, it is dropped if contract is no longer cached
+func (cache *contractCache) setModule(hash common.Hash, module *wasm.Module) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, ok := cache.items[hash]; ok {
+		element.Value.(*contractCacheItem).module = module
+	}
+}
+
+func (cache *contractCache) len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.order.Len()
+}
+
+func (cache *contractCache) purge() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.items = make(map[common.Hash]*list.Element)
+	cache.order.Init()
+}
+
+// LoadContract returns decoded contract of account, decoded contracts are cached by contract hash
+func LoadContract(account *storage.Account) (*abi.Contract, error) {
+	if contract, ok := contracts.get(account.ContractHash); ok {
+		return contract, nil
+	}
+	contract, err := account.GetContract()
+	if err != nil {
+		return nil, err
+	}
+	contracts.add(account.ContractHash, contract)
+	return contract, nil
+}
+
+// loadModule returns parsed wasm module of contract of account, parsed modules are cached with contracts
+func loadModule(account *storage.Account, contract *abi.Contract) (*wasm.Module, error) {
+	if module, ok := contracts.getModule(account.ContractHash); ok {
+		return module, nil
+	}
+	module, err := wasm.ReadModule(contract.Code)
+	if err != nil {
+		return nil, err
+	}
+	contracts.setModule(account.ContractHash, module)
+	return module, nil
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/db"
+	"github.com/QuoineFinancial/liquid-chain/gas"
+	"github.com/QuoineFinancial/liquid-chain/storage"
+)
+
+func TestContractCache(t *testing.T) {
+	cache := newContractCache(2)
+	first, second, third := &abi.Contract{}, &abi.Contract{}, &abi.Contract{}
+	cache.add(common.BytesToHash([]byte{1}), first)
+	cache.add(common.BytesToHash([]byte{2}), second)
+
+	// Reading first makes second the least recently used one
+	if contract, ok := cache.get(common.BytesToHash([]byte{1})); !ok || contract != first {
+		t.Fatalf("Expect first contract cached, got %v", contract)
+	}
+	cache.add(common.BytesToHash([]byte{3}), third)
+	if _, ok := cache.get(common.BytesToHash([]byte{2})); ok {
+		t.Errorf("Expect second contract evicted")
+	}
+	if contract, ok := cache.get(common.BytesToHash([]byte{3})); !ok || contract != third {
+		t.Errorf("Expect third contract cached, got %v", contract)
+	}
+	if cache.len() != 2 {
+		t.Errorf("Expect 2 cached contracts, got %v", cache.len())
+	}
+}
+
+func TestLoadContract(t *testing.T) {
+	contracts.purge()
+	state := storage.NewStateStorage(db.NewMemoryDB())
+	state.LoadState(&crypto.GenesisBlock)
+	address, _ := crypto.AddressFromString("LCR57ROUHIQ2AV4D3E3D7ZBTR6YXMKZQWTI4KSHSWCUCRXBKNJKKBCNY")
+	contractBytes, _ := rlp.EncodeToBytes(loadContract("testdata/revert-abi.json", "testdata/revert.wasm"))
+	account, _ := state.CreateAccount(crypto.EmptyAddress, address, contractBytes)
+
+	var wg sync.WaitGroup
+	loaded := make([]*abi.Contract, 8)
+	for i := range loaded {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loaded[i], _ = LoadContract(account)
+		}(i)
+	}
+	wg.Wait()
+	cached, _ := LoadContract(account)
+	if _, err := cached.Header.GetFunction("write"); err != nil {
+		t.Fatalf("Expect contract decoded, got %v", err)
+	}
+	for _, contract := range loaded {
+		if contract == nil {
+			t.Fatalf("Expect contract loaded concurrently")
+		}
+	}
+	if contracts.len() != 1 {
+		t.Errorf("Expect 1 cached contract, got %v", contracts.len())
+	}
+
+	// Upgraded contract is cached by its new hash
+	contractBytes, _ = rlp.EncodeToBytes(loadContract("testdata/return-data-abi.json", "testdata/return-data.wasm"))
+	account.SetContract(contractBytes)
+	upgraded, _ := LoadContract(account)
+	if _, err := upgraded.Header.GetFunction("values"); upgraded == cached || err != nil {
+		t.Errorf("Expect upgraded contract loaded, got %v", err)
+	}
+
+	if _, err := LoadContract(&storage.Account{}); err == nil {
+		t.Errorf("Expect error for account without contract")
+	}
+}
+
+func TestLoadModule(t *testing.T) {
+	contracts.purge()
+	state := storage.NewStateStorage(db.NewMemoryDB())
+	state.LoadState(&crypto.GenesisBlock)
+	address, _ := crypto.AddressFromString("LCR57ROUHIQ2AV4D3E3D7ZBTR6YXMKZQWTI4KSHSWCUCRXBKNJKKBCNY")
+	contractBytes, _ := rlp.EncodeToBytes(loadContract("testdata/revert-abi.json", "testdata/revert.wasm"))
+	account, _ := state.CreateAccount(crypto.EmptyAddress, address, contractBytes)
+	contract, _ := LoadContract(account)
+
+	module, err := loadModule(account, contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := loadModule(account, contract); cached != module {
+		t.Errorf("Expect module parsed once and cached")
+	}
+
+	// Engines share the cached module, each runs in its own memory
+	args, _ := abi.EncodeFromString([]*abi.Parameter{}, []string{})
+	for i := 0; i < 2; i++ {
+		if _, err := NewEngine(state, account, crypto.EmptyAddress, &gas.FreePolicy{}, 0).Ignite("write", args); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if value, _ := account.GetStorage([]byte("key")); len(value) == 0 {
+		t.Errorf("Expect contract run by cached module")
+	}
+
+	// Module is dropped with its contract
+	contracts.purge()
+	contracts.setModule(account.ContractHash, module)
+	if _, ok := contracts.getModule(account.ContractHash); ok {
+		t.Errorf("Expect module not cached without contract")
+	}
+
+	if _, err := loadModule(account, &abi.Contract{Code: []byte{0}}); err == nil {
+		t.Errorf("Expect error for invalid wasm code")
+	}
+}
+
+func benchmarkTransfer(b *testing.B, cached bool) {
+	state := storage.NewStateStorage(db.NewMemoryDB())
+	state.LoadState(&crypto.GenesisBlock)
+	tokenAddress, _ := crypto.AddressFromString("LBAPQ4LVHFYZQXRSS3CCN6VUZ2EEC6IN5S2RGQLHS3RNNOIBNP4B6XNH")
+	owner, _ := crypto.AddressFromString("LA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCTXXT")
+	contract := loadContract("../test/testdata/liquid-token-abi.json", "../test/testdata/liquid-token.wasm")
+	contractBytes, _ := rlp.EncodeToBytes(contract)
+	account, _ := state.CreateAccount(owner, tokenAddress, contractBytes)
+
+	initFunction, _ := contract.Header.GetFunction("init")
+	initArgs, _ := abi.EncodeFromString(initFunction.Parameters, []string{"1000000000"})
+	if _, err := NewEngine(state, account, owner, &gas.FreePolicy{}, 0).Ignite("init", initArgs); err != nil {
+		b.Fatal(err)
+	}
+	transfer, _ := contract.Header.GetFunction("transfer")
+	args, _ := abi.EncodeFromString(transfer.Parameters, []string{tokenAddress.String(), "1"})
+
+	contracts.purge()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !cached {
+			contracts.purge()
+		}
+		if _, err := NewEngine(state, account, owner, &gas.FreePolicy{}, 0).Ignite("transfer", args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTransfer(b *testing.B) {
+	b.Run("cached", func(b *testing.B) { benchmarkTransfer(b, true) })
+	b.Run("uncached", func(b *testing.B) { benchmarkTransfer(b, false) })
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/vertexdlt/vertexvm/vm"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	hashSize                = 32
+	secp256k1SignatureSize  = 64
+	secp256k1RecoverIDIndex = 64
+
+	secp256k1CompressedPublicKeySize   = 33
+	secp256k1UncompressedPublicKeySize = 65
+)
+
+// hashHostFunction returns host function which writes digest of data at dataPtr to outPtr
+func (engine *Engine) hashHostFunction(digest func([]byte) []byte) vm.HostFunction {
+	return func(vm *vm.VM, args ...uint64) (uint64, error) {
+		dataPtr, dataSize, outPtr := int(args[0]), int(args[1]), int(args[2])
+		// Burn gas before actually execute
+		if err := vm.BurnGas(engine.hostPolicy.GetCostForHash(dataSize)); err != nil {
+			return 0, err
+		}
+		data, err := readAt(vm, dataPtr, dataSize)
+		if err != nil {
+			return 0, err
+		}
+		_, err = vm.MemWrite(digest(data), outPtr)
+		return 0, err
+	}
+}
+
+func blake2b256(data []byte) []byte {
+	hash := blake2b.Sum256(data)
+	return hash[:]
+}
+
+func sha256Digest(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+func keccak256(data []byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(data)
+	return hasher.Sum(nil)
+}
+
+// chainSecp256k1Recover recovers public key from 32 bytes hash and 65 bytes signature r || s || v,
+// where v is 0, 1 or 27, 28 as of Ethereum. The 65 bytes uncompressed public key is written to outPtr.
+// It returns 1 if public key is recovered, otherwise 0
+func (engine *Engine) chainSecp256k1Recover(vm *vm.VM, args ...uint64) (uint64, error) {
+	hashPtr, signaturePtr, outPtr := int(args[0]), int(args[1]), int(args[2])
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForSecp256k1()); err != nil {
+		return 0, err
+	}
+	hash, err := readAt(vm, hashPtr, hashSize)
+	if err != nil {
+		return 0, err
+	}
+	signature, err := readAt(vm, signaturePtr, secp256k1SignatureSize+1)
+	if err != nil {
+		return 0, err
+	}
+
+	recoverID := signature[secp256k1RecoverIDIndex]
+	if recoverID >= 27 {
+		recoverID -= 27
+	}
+	if recoverID > 1 {
+		return 0, nil
+	}
+	// btcec expects compact signature as 27 + v || r || s
+	compact := append([]byte{27 + recoverID}, signature[:secp256k1SignatureSize]...)
+	publicKey, _, err := btcec.RecoverCompact(btcec.S256(), compact, hash)
+	if err != nil {
+		return 0, nil
+	}
+	if _, err := vm.MemWrite(publicKey.SerializeUncompressed(), outPtr); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// chainSecp256k1Verify verifies 64 bytes signature r || s of 32 bytes hash by
+// 33 bytes compressed or 65 bytes uncompressed public key. Other sizes fail before memory is read.
+// It returns 1 if signature is valid, otherwise 0
+func (engine *Engine) chainSecp256k1Verify(vm *vm.VM, args ...uint64) (uint64, error) {
+	publicKeyPtr, publicKeySize := int(args[0]), int(args[1])
+	hashPtr, hashLength := int(args[2]), int(args[3])
+	signaturePtr, signatureSize := int(args[4]), int(args[5])
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForSecp256k1()); err != nil {
+		return 0, err
+	}
+	if publicKeySize != secp256k1CompressedPublicKeySize && publicKeySize != secp256k1UncompressedPublicKeySize {
+		return 0, fmt.Errorf("invalid secp256k1 public key size %d", publicKeySize)
+	}
+	if hashLength != hashSize {
+		return 0, fmt.Errorf("invalid secp256k1 hash size %d", hashLength)
+	}
+	if signatureSize != secp256k1SignatureSize {
+		return 0, fmt.Errorf("invalid secp256k1 signature size %d", signatureSize)
+	}
+	rawPublicKey, err := readAt(vm, publicKeyPtr, publicKeySize)
+	if err != nil {
+		return 0, err
+	}
+	hash, err := readAt(vm, hashPtr, hashSize)
+	if err != nil {
+		return 0, err
+	}
+	rawSignature, err := readAt(vm, signaturePtr, secp256k1SignatureSize)
+	if err != nil {
+		return 0, err
+	}
+
+	publicKey, err := btcec.ParsePubKey(rawPublicKey, btcec.S256())
+	if err != nil {
+		return 0, nil
+	}
+	signature := &btcec.Signature{
+		R: new(big.Int).SetBytes(rawSignature[:32]),
+		S: new(big.Int).SetBytes(rawSignature[32:]),
+	}
+	if !signature.Verify(hash, publicKey) {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// chainEd25519VerifyMessage verifies 64 bytes signature of message by 32 bytes public key.
+// It returns 1 if signature is valid, otherwise 0
+func (engine *Engine) chainEd25519VerifyMessage(vm *vm.VM, args ...uint64) (uint64, error) {
+	publicKeyPtr, messagePtr, messageSize, signaturePtr := int(args[0]), int(args[1]), int(args[2]), int(args[3])
+	if err := vm.BurnGas(engine.hostPolicy.GetCostForEd25519(messageSize)); err != nil {
+		return 0, err
+	}
+	publicKey, err := readAt(vm, publicKeyPtr, ed25519.PublicKeySize)
+	if err != nil {
+		return 0, err
+	}
+	message, err := readAt(vm, messagePtr, messageSize)
+	if err != nil {
+		return 0, err
+	}
+	signature, err := readAt(vm, signaturePtr, ed25519.SignatureSize)
+	if err != nil {
+		return 0, err
+	}
+	if !ed25519.Verify(publicKey, message, signature) {
+		return 0, nil
+	}
+	return 1, nil
+}
@@ -16,11 +16,29 @@ import (
 const (
 	// ExportSecDataEnd is wasm export section key for __data_end
 	ExportSecDataEnd = "__data_end"
+
+	// InitFunctionName is function run when contract is deployed
+	InitFunctionName = "init"
+)
+
+var (
+	// ErrStaticCall used when contract modifies state in read-only engine
+	ErrStaticCall = errors.New("state modification in static call")
+)
+
+// Status of the last cross-contract call
+const (
+	CallStatusSuccess uint64 = 0
+	CallStatusFailure uint64 = 1
 )
 
 type foreignMethod struct {
 	contractAddress crypto.Address
 	name            string
+	// try calls return failure status to caller instead of aborting
+	try bool
+	// static calls are executed in read-only engine
+	static bool
 }
 
 // Engine is space to execute function
@@ -29,13 +47,23 @@ type Engine struct {
 	account       *storage.Account
 	caller        crypto.Address
 	gasPolicy     gas.Policy
+	hostPolicy    gas.Policy
 	callDepth     int
 	memAggr       int
 	events        []*crypto.Event
 	methodLookup  map[string]*foreignMethod
 	ptrArgSizeMap map[int]int
-	gas           *vertex.Gas
-	parent        *Engine
+	iterators     []*storage.StorageIterator
+	callStatus    uint64
+	returnData    []byte
+	// callReturnData is return data of the last cross-contract call
+	callReturnData []byte
+	gas            *vertex.Gas
+	// gasRefund is refund of this engine, merged into parent when the call succeeds
+	gasRefund uint64
+	parent    *Engine
+	// static engine fails on storage writes, events and deploys
+	static bool
 }
 
 // NewEngine return new instance of Engine
@@ -45,6 +73,7 @@ func NewEngine(state *storage.StateStorage, account *storage.Account, caller cry
 		caller:        caller,
 		account:       account,
 		gasPolicy:     gasPolicy,
+		hostPolicy:    gas.HostFunctionPolicy(gasPolicy),
 		events:        []*crypto.Event{},
 		methodLookup:  make(map[string]*foreignMethod),
 		ptrArgSizeMap: make(map[int]int),
@@ -53,14 +82,39 @@ func NewEngine(state *storage.StateStorage, account *storage.Account, caller cry
 	}
 }
 
+// NewStaticEngine returns new instance of read-only Engine
+func NewStaticEngine(state *storage.StateStorage, account *storage.Account, caller crypto.Address, gasPolicy gas.Policy, gasLimit uint64) *Engine {
+	engine := NewEngine(state, account, caller, gasPolicy, gasLimit)
+	engine.static = true
+	return engine
+}
+
+// IsStatic returns whether engine is read-only
+func (engine *Engine) IsStatic() bool {
+	return engine.static
+}
+
 // GetEvents return the event of engine
 func (engine *Engine) GetEvents() []*crypto.Event {
 	return engine.events
 }
 
-// GetGasUsed return gas used by vm
+// GetReturnData returns data set by contract through chain_return_data
+func (engine *Engine) GetReturnData() []byte {
+	return engine.returnData
+}
+
+// GetGasUsed return gas used by vm after refund, which is capped at half of the used gas
 func (engine *Engine) GetGasUsed() uint64 {
-	return engine.gas.Used
+	refund := engine.gasRefund
+	if maxRefund := engine.gas.Used / 2; refund > maxRefund {
+		refund = maxRefund
+	}
+	return engine.gas.Used - refund
+}
+
+func (engine *Engine) refundGas(amount uint64) {
+	engine.gasRefund += amount
 }
 
 // newChildEngine share with parent state except caller is contract itself
@@ -70,21 +124,28 @@ func (engine *Engine) newChildEngine(account *storage.Account) *Engine {
 		state:         engine.state,
 		caller:        engine.account.GetAddress(),
 		gasPolicy:     engine.gasPolicy,
+		hostPolicy:    engine.hostPolicy,
 		events:        []*crypto.Event{},
 		methodLookup:  make(map[string]*foreignMethod),
 		ptrArgSizeMap: make(map[int]int),
 		gas:           engine.gas,
 		parent:        engine,
+		static:        engine.static,
 	}
 }
 
 // Ignite executes a contract given its code, method, and arguments
 func (engine *Engine) Ignite(method string, methodArgs []byte) (uint64, error) {
-	contract, err := engine.account.GetContract()
+	engine.returnData = nil
+	contract, err := LoadContract(engine.account)
 	if err != nil {
 		return 0, err
 	}
-	vm, err := vertex.NewVM(contract.Code, engine.gasPolicy, engine.gas, engine)
+	module, err := loadModule(engine.account, contract)
+	if err != nil {
+		return 0, err
+	}
+	vm, err := vertex.NewVMFromModule(module, engine.gasPolicy, engine.gas, engine)
 	if err != nil {
 		return 0, err
 	}
@@ -111,6 +172,10 @@ func (engine *Engine) Ignite(method string, methodArgs []byte) (uint64, error) {
 		return 0, err
 	}
 	ret, err := vm.Invoke(funcID, arguments...)
+	if err != nil {
+		// Storage freed by failed execution is reverted, so is its refund
+		engine.gasRefund = 0
+	}
 	return ret, err
 }
 
@@ -129,13 +194,25 @@ func (engine *Engine) loadArguments(vm *vertex.VM, byteArgs [][]byte, params []*
 		return []uint64{}, fmt.Errorf("arguments byte size exceeds limit")
 	}
 	for i, bytes := range byteArgs {
-		isArray := params[i].IsArray || params[i].Type.IsAddress()
-		if isArray {
-			if params[i].Type.IsAddress() {
+		if params[i].Type == abi.Tuple {
+			memory, err := engine.writeTupleArgument(params[i], bytes, offset)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := vm.MemWrite(memory, offset); err != nil {
+				return nil, err
+			}
+			args[i] = uint64(offset)
+			offset += len(memory)
+		} else if params[i].IsPointer() {
+			if params[i].Type.IsAddress() && !params[i].IsArray {
 				if _, err := crypto.AddressFromBytes(bytes); err != nil {
 					return nil, err
 				}
 			}
+			if size := params[i].GetMemorySize(); size > 0 && len(bytes) != size {
+				return nil, fmt.Errorf("argument %s size mismatch", params[i].Name)
+			}
 			if _, err := vm.MemWrite(bytes, offset); err != nil {
 				return nil, err
 			}
@@ -163,10 +240,22 @@ func (engine *Engine) ptrArgSizeSet(ptr int, size int) {
 	engine.ptrArgSizeMap[ptr] = size
 }
 
+func (engine *Engine) getIterator(id uint64) (*storage.StorageIterator, error) {
+	if id >= uint64(len(engine.iterators)) {
+		return nil, errors.New("storage iterator not found")
+	}
+	return engine.iterators[id], nil
+}
+
+// pushEvent keeps event in engine, events of child engine are pushed to parent once the call succeeds
 func (engine *Engine) pushEvent(event *crypto.Event) {
-	if engine.parent != nil {
-		engine.parent.pushEvent(event)
-	} else {
-		engine.events = append(engine.events, event)
+	engine.events = append(engine.events, event)
+}
+
+// requireWritable fails with ErrStaticCall if engine is read-only
+func (engine *Engine) requireWritable() error {
+	if engine.static {
+		return ErrStaticCall
 	}
+	return nil
 }
@@ -1,7 +1,9 @@
 package engine
 
 import (
+	"bytes"
 	"crypto/ed25519"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io/ioutil"
@@ -293,3 +295,120 @@ func TestDelegatedCall(t *testing.T) {
 		t.Errorf("Engine.Ignite() = %v, want %v", got, 0)
 	}
 }
+
+func TestTupleArguments(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.FreePolicy{}, 10000)
+	encodedHeader, err := abi.EncodeHeaderJSONToBytes([]byte(`{"version":2,
+		"structs":[{"name":"Order","fields":[{"name":"id","type":"uint32"},{"name":"owner","type":"address"},{"name":"prices","type":"uint64[]"}]}],
+		"events":[],
+		"functions":[{"name":"place","parameters":[
+			{"name":"orders","type":"Order[]"},
+			{"name":"pair","type":"tuple","fields":[{"name":"base","type":"uint8"},{"name":"quote","type":"uint8"}]}
+		]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _ := abi.DecodeHeader(encodedHeader)
+	function, _ := header.GetFunction("place")
+	owner, _ := crypto.AddressFromString("LCHILMXMODD5DMDMPKVSD5MUODDQMBRU5GZVLGXEFBPG36HV4CLSYM7O")
+	encoded, err := abi.Encode(function.Parameters, []interface{}{
+		[]interface{}{[]interface{}{uint32(1), owner, []uint64{10, 20}}},
+		[]interface{}{uint8(3), uint8(4)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byteArgs, _ := abi.DecodeToBytes(function.Parameters, encoded)
+
+	args, err := engine.loadArguments(vm, byteArgs, function.Parameters, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderSize := 4 + crypto.AddressLength + tuplePointerSize
+	if size, _ := engine.ptrArgSizeGet(int(args[0])); size != orderSize {
+		t.Errorf("Expect size of orders %d, got %d", orderSize, size)
+	}
+	order, _ := readAt(vm, int(args[0]), orderSize)
+	if id := binary.LittleEndian.Uint32(order); id != 1 {
+		t.Errorf("Expect order id 1, got %d", id)
+	}
+	if !bytes.Equal(order[4:4+crypto.AddressLength], owner[:]) {
+		t.Errorf("Expect order owner %v, got %v", owner[:], order[4:4+crypto.AddressLength])
+	}
+	pricesPtr := int(binary.LittleEndian.Uint32(order[4+crypto.AddressLength:]))
+	prices, _ := readAt(vm, pricesPtr, 16)
+	if binary.LittleEndian.Uint64(prices) != 10 || binary.LittleEndian.Uint64(prices[8:]) != 20 {
+		t.Errorf("Expect prices [10 20], got %v", prices)
+	}
+	if pair, _ := readAt(vm, int(args[1]), 2); !bytes.Equal(pair, []byte{3, 4}) {
+		t.Errorf("Expect pair [3 4], got %v", pair)
+	}
+
+	for i, param := range function.Parameters {
+		read, err := engine.readTupleArgument(vm, param, int(args[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(read, byteArgs[i]) {
+			t.Errorf("Expect %s read from memory as loaded, got %v", param.Name, read)
+		}
+	}
+}
+
+func TestTupleEvent(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.FreePolicy{}, 10000)
+	encodedHeader, err := abi.EncodeHeaderJSONToBytes([]byte(`{"version":2,
+		"structs":[{"name":"Order","fields":[{"name":"id","type":"uint32"},{"name":"memo","type":"string"}]}],
+		"events":[{"name":"Placed","parameters":[{"name":"order","type":"Order"}]}],
+		"functions":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := abi.DecodeHeader(encodedHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, _ := header.GetEvent("Placed")
+
+	// Contract lays out the order at 0 and its memo at 100
+	orderPtr, memoPtr := 0, 100
+	order := make([]byte, 4+tuplePointerSize)
+	binary.LittleEndian.PutUint32(order, 7)
+	binary.LittleEndian.PutUint32(order[4:], uint32(memoPtr))
+	vm.MemWrite(order, orderPtr)
+	vm.MemWrite([]byte("alice"), memoPtr)
+
+	if _, err := engine.handleEmitEvent(event, vm, uint64(orderPtr)); err == nil || err.Error() != "pointer size not found" {
+		t.Errorf("Expect memo without size to fail with pointer size not found, got %v", err)
+	}
+
+	engine.ptrArgSizeSet(memoPtr, len("alice"))
+	if _, err := engine.handleEmitEvent(event, vm, uint64(orderPtr)); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := abi.Encode(event.Parameters, []interface{}{[]interface{}{uint32(7), "alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := engine.GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("Expect 1 event, got %d", len(events))
+	}
+	if !bytes.Equal(events[0].Args, expected) {
+		t.Errorf("Expect event args %v, got %v", expected, events[0].Args)
+	}
+}
+
+func TestTupleWithoutStruct(t *testing.T) {
+	engine, vm := newHostTestEngine(&gas.FreePolicy{}, 10000)
+	param := &abi.Parameter{Name: "order", Type: abi.Tuple}
+	if _, err := engine.writeTupleArgument(param, nil, 0); err != errTupleWithoutStruct {
+		t.Errorf("Expect write to fail with %v, got %v", errTupleWithoutStruct, err)
+	}
+	if _, err := engine.readTupleArgument(vm, param, 0); err != errTupleWithoutStruct {
+		t.Errorf("Expect read to fail with %v, got %v", errTupleWithoutStruct, err)
+	}
+	if size := tupleSize(nil); size != 0 {
+		t.Errorf("Expect size of nil struct 0, got %d", size)
+	}
+}
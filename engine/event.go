@@ -9,10 +9,13 @@ import (
 )
 
 func (engine *Engine) handleEmitEvent(eventHeader *abi.Event, vm *vm.VM, args ...uint64) (uint64, error) {
+	if err := engine.requireWritable(); err != nil {
+		return 0, err
+	}
 	var memBytes [][]byte
 	for i, param := range eventHeader.Parameters {
-		switch param.Type {
-		case abi.Address:
+		switch {
+		case param.Type.IsAddress() && !param.IsArray:
 			paramPtr := int(uint32(args[i]))
 			size := param.Type.GetMemorySize()
 			memValue, err := readAt(vm, paramPtr, size)
@@ -23,21 +26,31 @@ func (engine *Engine) handleEmitEvent(eventHeader *abi.Event, vm *vm.VM, args ..
 				return 0, err
 			}
 			memBytes = append(memBytes, memValue)
-		default:
-			if param.IsArray {
-				paramPtr := int(uint32(args[i]))
-				size, err := engine.ptrArgSizeGet(paramPtr)
-				if err != nil {
+		case param.Type == abi.Tuple:
+			memValue, err := engine.readTupleArgument(vm, param, int(uint32(args[i])))
+			if err != nil {
+				return 0, err
+			}
+			memBytes = append(memBytes, memValue)
+		case param.IsPointer():
+			paramPtr := int(uint32(args[i]))
+			size := param.GetMemorySize()
+			if size == 0 {
+				var err error
+				if size, err = engine.ptrArgSizeGet(paramPtr); err != nil {
 					return 0, err
 				}
-				array, err := readAt(vm, paramPtr, size)
-				memBytes = append(memBytes, array)
-			} else {
-				size := abi.Uint64.GetMemorySize()
-				value := make([]byte, size)
-				binary.LittleEndian.PutUint64(value, args[i])
-				memBytes = append(memBytes, value)
 			}
+			memValue, err := readAt(vm, paramPtr, size)
+			if err != nil {
+				return 0, err
+			}
+			memBytes = append(memBytes, memValue)
+		default:
+			size := abi.Uint64.GetMemorySize()
+			value := make([]byte, size)
+			binary.LittleEndian.PutUint64(value, args[i])
+			memBytes = append(memBytes, value)
 		}
 	}
 
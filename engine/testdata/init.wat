@@ -0,0 +1,17 @@
+(module
+  (type $t0 (func (param i32 i32 i32 i32)))
+  (type $t1 (func (result i32)))
+  (import "env" "chain_storage_set" (func $env.chain_storage_set (type $t0)))
+  (func $init (type $t1) (result i32)
+    i32.const 0
+    i32.const 4
+    i32.const 4
+    i32.const 4
+    call $env.chain_storage_set
+    i32.const 1)
+  (memory $memory 1)
+  (global $__data_end i32 (i32.const 1024))
+  (export "memory" (memory 0))
+  (export "__data_end" (global 0))
+  (export "init" (func $init))
+  (data (i32.const 0) "initdone"))
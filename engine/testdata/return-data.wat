@@ -0,0 +1,15 @@
+(module
+  (type $t0 (func (param i32 i32)))
+  (type $t1 (func (result i32)))
+  (import "env" "chain_return_data" (func $env.chain_return_data (type $t0)))
+  (func $values (type $t1) (result i32)
+    i32.const 0
+    i32.const 12
+    call $env.chain_return_data
+    i32.const 3)
+  (memory $memory 1)
+  (global $__data_end i32 (i32.const 1024))
+  (export "memory" (memory 0))
+  (export "__data_end" (global 0))
+  (export "values" (func $values))
+  (data (i32.const 0) "\01\00\00\00\02\00\00\00\03\00\00\00"))
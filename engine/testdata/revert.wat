@@ -0,0 +1,38 @@
+(module
+  (type $t0 (func (param i32 i32 i32 i32)))
+  (type $t1 (func (result i32)))
+  (type $t2 (func))
+  (type $t3 (func (param i32 i32) (result i32)))
+  (import "env" "chain_storage_set" (func $env.chain_storage_set (type $t0)))
+  (import "env" "stored" (func $env.stored (type $t2)))
+  (import "env" "chain_storage_delete" (func $env.chain_storage_delete (type $t3)))
+  (func $write (type $t1) (result i32)
+    i32.const 0
+    i32.const 3
+    i32.const 3
+    i32.const 5
+    call $env.chain_storage_set
+    call $env.stored
+    i32.const 1)
+  (func $fail (type $t1) (result i32)
+    i32.const 8
+    i32.const 3
+    i32.const 3
+    i32.const 5
+    call $env.chain_storage_set
+    call $env.stored
+    unreachable)
+  (func $clear (type $t1) (result i32)
+    i32.const 0
+    i32.const 3
+    call $env.chain_storage_delete
+    drop
+    unreachable)
+  (memory $memory 1)
+  (global $__data_end i32 (i32.const 1024))
+  (export "memory" (memory 0))
+  (export "__data_end" (global 0))
+  (export "write" (func $write))
+  (export "fail" (func $fail))
+  (export "clear" (func $clear))
+  (data (i32.const 0) "keyvaluebad"))
@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/vertexdlt/vertexvm/vm"
+)
+
+// Tuples are laid out in memory as packed fields in order. Fields of fixed memory size
+// and nested tuples are inlined, other fields are 4 bytes pointers to their data,
+// which size is kept as pointer size. An array of tuples is a pointer to its tuples
+// laid out one after another.
+//
+// Sizes of pointer data are kept by host, not in the layout. Tuples written by host have them,
+// a contract laying out a tuple itself, to emit or pass it to another contract, sets the size
+// of every pointer field and array of tuples by chain_arg_size_set, otherwise reading it fails.
+const tuplePointerSize = 4
+
+var errTupleWithoutStruct = errors.New("tuple parameter without struct")
+
+// tupleSize returns memory size of tuple layout, excluding data of pointer fields
+func tupleSize(s *abi.Struct) int {
+	if s == nil {
+		return 0
+	}
+	size := 0
+	for _, field := range s.Fields {
+		size += tupleFieldSize(field)
+	}
+	return size
+}
+
+func tupleFieldSize(field *abi.Parameter) int {
+	if field.Type == abi.Tuple && !field.IsArray {
+		return tupleSize(field.Struct())
+	}
+	if size := field.GetMemorySize(); size > 0 {
+		return size
+	}
+	return tuplePointerSize
+}
+
+// tupleWriter lays out tuples in a memory region starting at offset
+type tupleWriter struct {
+	engine *Engine
+	offset int
+	memory []byte
+}
+
+// writeTupleArgument lays out a tuple or an array of tuples at offset, it returns the region
+func (engine *Engine) writeTupleArgument(param *abi.Parameter, bytes []byte, offset int) ([]byte, error) {
+	if param.Struct() == nil {
+		return nil, errTupleWithoutStruct
+	}
+	writer := &tupleWriter{engine: engine, offset: offset}
+	if param.IsArray {
+		if _, err := writer.appendPointerData(param, bytes); err != nil {
+			return nil, err
+		}
+		return writer.memory, nil
+	}
+	s := param.Struct()
+	writer.memory = make([]byte, tupleSize(s))
+	engine.ptrArgSizeMap[offset] = len(writer.memory)
+	if err := writer.writeTuple(s, bytes, 0); err != nil {
+		return nil, err
+	}
+	return writer.memory, nil
+}
+
+// writeTuple writes fields of tuple at position, data of pointer fields is appended to memory
+func (writer *tupleWriter) writeTuple(s *abi.Struct, bytes []byte, position int) error {
+	if s == nil {
+		return errTupleWithoutStruct
+	}
+	fields, err := abi.DecodeTuple(s, bytes)
+	if err != nil {
+		return err
+	}
+	for i, field := range s.Fields {
+		switch {
+		case field.Type == abi.Tuple && !field.IsArray:
+			if err := writer.writeTuple(field.Struct(), fields[i], position); err != nil {
+				return err
+			}
+		case field.GetMemorySize() > 0:
+			if len(fields[i]) != field.GetMemorySize() {
+				return errors.New("tuple field size mismatch")
+			}
+			copy(writer.memory[position:], fields[i])
+		default:
+			ptr, err := writer.appendPointerData(field, fields[i])
+			if err != nil {
+				return err
+			}
+			binary.LittleEndian.PutUint32(writer.memory[position:], uint32(ptr))
+		}
+		position += tupleFieldSize(field)
+	}
+	return nil
+}
+
+// appendPointerData appends data of a pointer field to memory and returns its pointer
+func (writer *tupleWriter) appendPointerData(field *abi.Parameter, bytes []byte) (int, error) {
+	position := len(writer.memory)
+	ptr := writer.offset + position
+	if field.Type != abi.Tuple {
+		writer.memory = append(writer.memory, bytes...)
+		writer.engine.ptrArgSizeMap[ptr] = len(bytes)
+		return ptr, nil
+	}
+
+	tuples, err := abi.DecodeTupleArray(field, bytes)
+	if err != nil {
+		return 0, err
+	}
+	s := field.Struct()
+	size := tupleSize(s)
+	writer.memory = append(writer.memory, make([]byte, len(tuples)*size)...)
+	writer.engine.ptrArgSizeMap[ptr] = len(tuples) * size
+	for i, tuple := range tuples {
+		if err := writer.writeTuple(s, tuple, position+i*size); err != nil {
+			return 0, err
+		}
+	}
+	return ptr, nil
+}
+
+// readTupleArgument reads a tuple or an array of tuples at ptr into its encoding
+func (engine *Engine) readTupleArgument(vm *vm.VM, param *abi.Parameter, ptr int) ([]byte, error) {
+	if param.Struct() == nil {
+		return nil, errTupleWithoutStruct
+	}
+	if param.IsArray {
+		return engine.readTupleArray(vm, param, ptr)
+	}
+	return engine.readTuple(vm, param.Struct(), ptr)
+}
+
+func (engine *Engine) readTuple(vm *vm.VM, s *abi.Struct, ptr int) ([]byte, error) {
+	if s == nil {
+		return nil, errTupleWithoutStruct
+	}
+	fields := [][]byte{}
+	for _, field := range s.Fields {
+		var value []byte
+		var err error
+		switch {
+		case field.Type == abi.Tuple && !field.IsArray:
+			value, err = engine.readTuple(vm, field.Struct(), ptr)
+		case field.GetMemorySize() > 0:
+			value, err = readAt(vm, ptr, field.GetMemorySize())
+		default:
+			var rawPtr []byte
+			if rawPtr, err = readAt(vm, ptr, tuplePointerSize); err != nil {
+				return nil, err
+			}
+			fieldPtr := int(binary.LittleEndian.Uint32(rawPtr))
+			if field.Type == abi.Tuple {
+				value, err = engine.readTupleArray(vm, field, fieldPtr)
+			} else {
+				var size int
+				if size, err = engine.ptrArgSizeGet(fieldPtr); err != nil {
+					return nil, err
+				}
+				value, err = readAt(vm, fieldPtr, size)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, value)
+		ptr += tupleFieldSize(field)
+	}
+	return rlp.EncodeToBytes(fields)
+}
+
+func (engine *Engine) readTupleArray(vm *vm.VM, param *abi.Parameter, ptr int) ([]byte, error) {
+	size, err := engine.ptrArgSizeGet(ptr)
+	if err != nil {
+		return nil, err
+	}
+	s := param.Struct()
+	elementSize := tupleSize(s)
+	if elementSize == 0 {
+		return nil, errTupleWithoutStruct
+	}
+	tuples := [][]byte{}
+	for position := 0; position+elementSize <= size; position += elementSize {
+		tuple, err := engine.readTuple(vm, s, ptr+position)
+		if err != nil {
+			return nil, err
+		}
+		tuples = append(tuples, tuple)
+	}
+	return rlp.EncodeToBytes(tuples)
+}
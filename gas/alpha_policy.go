@@ -205,11 +205,71 @@ func (p *AlphaPolicy) GetCostForOp(op opcode.Opcode) uint64 {
 	return gasAlphaTable[op]
 }
 
+// GetCostForHostCall is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForHostCall() uint64 {
+	return 0
+}
+
+// GetCostForMemory is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForMemory(size int) uint64 {
+	return 0
+}
+
 // GetCostForStorage size of data
 func (p *AlphaPolicy) GetCostForStorage(size int) uint64 {
 	return uint64(size)
 }
 
+// GetCostForStorageKey is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForStorageKey(size int) uint64 {
+	return 0
+}
+
+// GetCostForStorageRead is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForStorageRead() uint64 {
+	return 0
+}
+
+// GetCostForStorageDelete is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForStorageDelete() uint64 {
+	return 0
+}
+
+// GetRefundForStorage is zero, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetRefundForStorage(size int) uint64 {
+	return 0
+}
+
+// GetCostForStorageIteration is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForStorageIteration() uint64 {
+	return 0
+}
+
+// GetCostForStorageIterationBytes is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForStorageIterationBytes(size int) uint64 {
+	return 0
+}
+
+// GetCostForHash is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForHash(size int) uint64 {
+	return 0
+}
+
+// GetCostForSecp256k1 is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForSecp256k1() uint64 {
+	return 0
+}
+
+// GetCostForEd25519 is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForEd25519(size int) uint64 {
+	return 0
+}
+
+// GetCostForCall is free, AlphaPolicy does not charge host functions
+func (p *AlphaPolicy) GetCostForCall() uint64 {
+	return 0
+}
+
 // GetCostForContract creation
 func (p *AlphaPolicy) GetCostForContract(size int) uint64 {
 	return uint64(size)
@@ -28,4 +28,25 @@ func TestAlphaPolicy(t *testing.T) {
 	if cost != GasMemoryPage {
 		t.Errorf("Expect cost %v, got %v", GasMemoryPage, cost)
 	}
+
+	// Host functions which existed before BetaPolicy are free under AlphaPolicy
+	hostCosts := []uint64{
+		policy.GetCostForHostCall(),
+		policy.GetCostForMemory(100),
+		policy.GetCostForStorageKey(100),
+		policy.GetCostForStorageRead(),
+		policy.GetCostForStorageDelete(),
+		policy.GetRefundForStorage(100),
+		policy.GetCostForStorageIteration(),
+		policy.GetCostForStorageIterationBytes(100),
+		policy.GetCostForHash(100),
+		policy.GetCostForSecp256k1(),
+		policy.GetCostForEd25519(100),
+		policy.GetCostForCall(),
+	}
+	for i, cost := range hostCosts {
+		if cost != 0 {
+			t.Errorf("Expect host cost %d free, got %v", i, cost)
+		}
+	}
 }
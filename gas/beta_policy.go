@@ -0,0 +1,97 @@
+package gas
+
+// Cost for host functions
+const (
+	// GasHostCall is base cost of every host function without other cost
+	GasHostCall    uint64 = 2
+	GasStorageRead uint64 = 50
+	GasCall        uint64 = 700
+
+	// GasStorageKeyByte is cost of every byte of storage key read, written or deleted
+	GasStorageKeyByte uint64 = 1
+)
+
+// Cost and refund of storage deletion
+const (
+	GasStorageDelete uint64 = 10
+
+	// GasStorageRefundQuotient refunds a part of storage cost for freed bytes
+	GasStorageRefundQuotient uint64 = 2
+)
+
+// GasStorageIteration is cost of opening or stepping a storage iterator
+const GasStorageIteration uint64 = 10
+
+// Cost for cryptographic host functions
+const (
+	GasHash      uint64 = 30
+	GasHashWord  uint64 = 6
+	GasSecp256k1 uint64 = 3000
+	GasEd25519   uint64 = 2000
+)
+
+// BetaPolicy charges host functions on top of AlphaPolicy.
+// Chains switch to it from an upgrade height, so blocks executed under AlphaPolicy replay with the same gas
+type BetaPolicy struct {
+	AlphaPolicy
+}
+
+// GetCostForHostCall returns base cost for calling a host function
+func (p *BetaPolicy) GetCostForHostCall() uint64 {
+	return GasHostCall
+}
+
+// GetCostForMemory returns cost for copying size bytes between host and VM memory
+func (p *BetaPolicy) GetCostForMemory(size int) uint64 {
+	return GasMemory * uint64(size)
+}
+
+// GetCostForStorageKey returns cost for size bytes of storage key
+func (p *BetaPolicy) GetCostForStorageKey(size int) uint64 {
+	return GasStorageKeyByte * uint64(size)
+}
+
+// GetCostForStorageRead returns cost for looking up a storage key
+func (p *BetaPolicy) GetCostForStorageRead() uint64 {
+	return GasStorageRead
+}
+
+// GetCostForStorageDelete returns cost for deleting a storage key
+func (p *BetaPolicy) GetCostForStorageDelete() uint64 {
+	return GasStorageDelete
+}
+
+// GetRefundForStorage returns refund for freed bytes of storage
+func (p *BetaPolicy) GetRefundForStorage(size int) uint64 {
+	return p.GetCostForStorage(size) / GasStorageRefundQuotient
+}
+
+// GetCostForStorageIteration returns base cost for opening or stepping a storage iterator
+func (p *BetaPolicy) GetCostForStorageIteration() uint64 {
+	return GasStorageIteration
+}
+
+// GetCostForStorageIterationBytes returns cost for size bytes of prefix, or key and value of an iterator step
+func (p *BetaPolicy) GetCostForStorageIterationBytes(size int) uint64 {
+	return uint64(size)
+}
+
+// GetCostForHash returns cost for hashing size bytes, charged by 32 bytes word
+func (p *BetaPolicy) GetCostForHash(size int) uint64 {
+	return GasHash + GasHashWord*((uint64(size)+31)/32)
+}
+
+// GetCostForSecp256k1 returns cost for secp256k1 signature verification or public key recovery
+func (p *BetaPolicy) GetCostForSecp256k1() uint64 {
+	return GasSecp256k1
+}
+
+// GetCostForEd25519 returns cost for ed25519 signature verification of size bytes message
+func (p *BetaPolicy) GetCostForEd25519(size int) uint64 {
+	return GasEd25519 + GasHashWord*((uint64(size)+31)/32)
+}
+
+// GetCostForCall returns base cost for calling another contract
+func (p *BetaPolicy) GetCostForCall() uint64 {
+	return GasCall
+}
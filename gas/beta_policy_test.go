@@ -0,0 +1,79 @@
+package gas
+
+import (
+	"testing"
+
+	"github.com/vertexdlt/vertexvm/opcode"
+)
+
+func TestBetaPolicy(t *testing.T) {
+	policy := BetaPolicy{}
+	cost := policy.GetCostForOp(opcode.Select)
+	if cost != 5 {
+		t.Errorf("Expect cost %v, got %v", 5, cost)
+	}
+	cost = policy.GetCostForHostCall()
+	if cost != GasHostCall {
+		t.Errorf("Expect cost %v, got %v", GasHostCall, cost)
+	}
+	cost = policy.GetCostForMemory(100)
+	if cost != 100*GasMemory {
+		t.Errorf("Expect cost %v, got %v", 100*GasMemory, cost)
+	}
+	cost = policy.GetCostForStorageRead()
+	if cost != GasStorageRead {
+		t.Errorf("Expect cost %v, got %v", GasStorageRead, cost)
+	}
+	cost = policy.GetCostForStorage(100)
+	if cost != 100 {
+		t.Errorf("Expect cost %v, got %v", 100, cost)
+	}
+	cost = policy.GetCostForStorageKey(100)
+	if cost != 100*GasStorageKeyByte {
+		t.Errorf("Expect cost %v, got %v", 100*GasStorageKeyByte, cost)
+	}
+	cost = policy.GetCostForStorageDelete()
+	if cost != GasStorageDelete {
+		t.Errorf("Expect cost %v, got %v", GasStorageDelete, cost)
+	}
+	cost = policy.GetRefundForStorage(100)
+	if cost != 50 {
+		t.Errorf("Expect refund %v, got %v", 50, cost)
+	}
+	cost = policy.GetCostForStorageIteration()
+	if cost != GasStorageIteration {
+		t.Errorf("Expect cost %v, got %v", GasStorageIteration, cost)
+	}
+	cost = policy.GetCostForStorageIterationBytes(100)
+	if cost != 100 {
+		t.Errorf("Expect cost %v, got %v", 100, cost)
+	}
+	cost = policy.GetCostForHash(100)
+	if cost != GasHash+4*GasHashWord {
+		t.Errorf("Expect cost %v, got %v", GasHash+4*GasHashWord, cost)
+	}
+	cost = policy.GetCostForSecp256k1()
+	if cost != GasSecp256k1 {
+		t.Errorf("Expect cost %v, got %v", GasSecp256k1, cost)
+	}
+	cost = policy.GetCostForEd25519(100)
+	if cost != GasEd25519+4*GasHashWord {
+		t.Errorf("Expect cost %v, got %v", GasEd25519+4*GasHashWord, cost)
+	}
+	cost = policy.GetCostForCall()
+	if cost != GasCall {
+		t.Errorf("Expect cost %v, got %v", GasCall, cost)
+	}
+	cost = policy.GetCostForContract(100)
+	if cost != 100 {
+		t.Errorf("Expect cost %v, got %v", 100, cost)
+	}
+	cost = policy.GetCostForEvent(100)
+	if cost != 100 {
+		t.Errorf("Expect cost %v, got %v", 100, cost)
+	}
+	cost = policy.GetCostForMalloc(1)
+	if cost != GasMemoryPage {
+		t.Errorf("Expect cost %v, got %v", GasMemoryPage, cost)
+	}
+}
@@ -87,6 +87,10 @@ func (app *DummyApp) GetGasContractToken() Token {
 	return nil
 }
 
+func (app *DummyApp) GetMinGasPrice() uint32 {
+	return DefaultMinimumGasPrice
+}
+
 func TestNewDummyStation(t *testing.T) {
 	app := &DummyApp{}
 	want := &DummyStation{
@@ -14,11 +14,71 @@ func (p *FreePolicy) GetCostForOp(op opcode.Opcode) uint64 {
 	return 0
 }
 
+// GetCostForHostCall returns base cost for calling a host function
+func (p *FreePolicy) GetCostForHostCall() uint64 {
+	return 0
+}
+
+// GetCostForMemory returns cost for copying size bytes between host and VM memory
+func (p *FreePolicy) GetCostForMemory(size int) uint64 {
+	return 0
+}
+
 // GetCostForStorage size of data
 func (p *FreePolicy) GetCostForStorage(size int) uint64 {
 	return 0
 }
 
+// GetCostForStorageKey returns cost for size bytes of storage key
+func (p *FreePolicy) GetCostForStorageKey(size int) uint64 {
+	return 0
+}
+
+// GetCostForStorageRead returns cost for looking up a storage key
+func (p *FreePolicy) GetCostForStorageRead() uint64 {
+	return 0
+}
+
+// GetCostForStorageDelete returns cost for deleting a storage key
+func (p *FreePolicy) GetCostForStorageDelete() uint64 {
+	return 0
+}
+
+// GetRefundForStorage returns refund for freed bytes of storage
+func (p *FreePolicy) GetRefundForStorage(size int) uint64 {
+	return 0
+}
+
+// GetCostForStorageIteration returns base cost for opening or stepping a storage iterator
+func (p *FreePolicy) GetCostForStorageIteration() uint64 {
+	return 0
+}
+
+// GetCostForStorageIterationBytes returns cost for size bytes of prefix, or key and value of an iterator step
+func (p *FreePolicy) GetCostForStorageIterationBytes(size int) uint64 {
+	return 0
+}
+
+// GetCostForHash returns cost for hashing size bytes
+func (p *FreePolicy) GetCostForHash(size int) uint64 {
+	return 0
+}
+
+// GetCostForSecp256k1 returns cost for secp256k1 signature verification or public key recovery
+func (p *FreePolicy) GetCostForSecp256k1() uint64 {
+	return 0
+}
+
+// GetCostForEd25519 returns cost for ed25519 signature verification of size bytes message
+func (p *FreePolicy) GetCostForEd25519(size int) uint64 {
+	return 0
+}
+
+// GetCostForCall returns base cost for calling another contract
+func (p *FreePolicy) GetCostForCall() uint64 {
+	return 0
+}
+
 // GetCostForContract creation
 func (p *FreePolicy) GetCostForContract(size int) uint64 {
 	return 0
@@ -12,10 +12,58 @@ func TestFreePolicy(t *testing.T) {
 	if cost != 0 {
 		t.Errorf("Expect cost %v, got %v", 0, cost)
 	}
+	cost = policy.GetCostForHostCall()
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForMemory(100)
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForStorageKey(100)
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForStorageRead()
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
 	cost = policy.GetCostForStorage(100)
 	if cost != 0 {
 		t.Errorf("Expect cost %v, got %v", 0, cost)
 	}
+	cost = policy.GetCostForStorageDelete()
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetRefundForStorage(100)
+	if cost != 0 {
+		t.Errorf("Expect refund %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForStorageIteration()
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForStorageIterationBytes(100)
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForHash(100)
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForSecp256k1()
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForEd25519(100)
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
+	cost = policy.GetCostForCall()
+	if cost != 0 {
+		t.Errorf("Expect cost %v, got %v", 0, cost)
+	}
 	cost = policy.GetCostForContract(100)
 	if cost != 0 {
 		t.Errorf("Expect cost %v, got %v", 0, cost)
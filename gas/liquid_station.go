@@ -4,7 +4,9 @@ import (
 	"github.com/QuoineFinancial/liquid-chain/crypto"
 )
 
-const minimumGasPrice = uint32(18)
+// DefaultMinimumGasPrice is used when genesis does not set a minimum gas price
+const DefaultMinimumGasPrice = uint32(18)
+
 const feeTranferMemo = uint64(0)
 
 // LiquidStation provide a liquid as a gas station
@@ -50,7 +52,7 @@ func (station *LiquidStation) GetPolicy() Policy {
 
 // CheckGasPrice of transaction
 func (station *LiquidStation) CheckGasPrice(price uint32) bool {
-	return price >= minimumGasPrice
+	return price >= station.app.GetMinGasPrice()
 }
 
 // NewLiquidStation with fee
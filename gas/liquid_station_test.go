@@ -42,6 +42,10 @@ func (app *MockApp) GetGasContractToken() Token {
 	return &MockToken{}
 }
 
+func (app *MockApp) GetMinGasPrice() uint32 {
+	return DefaultMinimumGasPrice
+}
+
 func TestSwitch(t *testing.T) {
 	app := &MockApp{}
 	contractAddress, _ := crypto.AddressFromString(contractAddressStr)
@@ -7,7 +7,28 @@ import (
 // Policy for gas cost
 type Policy interface {
 	vm.GasPolicy
+	GetCostForHostCall() uint64
+	GetCostForMemory(size int) uint64
 	GetCostForStorage(size int) uint64
+	GetCostForStorageKey(size int) uint64
+	GetCostForStorageRead() uint64
+	GetCostForStorageDelete() uint64
+	GetRefundForStorage(size int) uint64
+	GetCostForStorageIteration() uint64
+	GetCostForStorageIterationBytes(size int) uint64
+	GetCostForHash(size int) uint64
+	GetCostForSecp256k1() uint64
+	GetCostForEd25519(size int) uint64
+	GetCostForCall() uint64
 	GetCostForContract(size int) uint64
 	GetCostForEvent(size int) uint64
 }
+
+// HostFunctionPolicy returns policy of host functions which did not exist under AlphaPolicy.
+// No block called them before BetaPolicy, so they are charged by BetaPolicy from the start
+func HostFunctionPolicy(policy Policy) Policy {
+	if _, ok := policy.(*AlphaPolicy); ok {
+		return &BetaPolicy{}
+	}
+	return policy
+}
@@ -0,0 +1,17 @@
+package gas
+
+import "testing"
+
+func TestHostFunctionPolicy(t *testing.T) {
+	if _, ok := HostFunctionPolicy(&AlphaPolicy{}).(*BetaPolicy); !ok {
+		t.Errorf("Expect BetaPolicy for AlphaPolicy")
+	}
+	beta := &BetaPolicy{}
+	if policy := HostFunctionPolicy(beta); policy != beta {
+		t.Errorf("Expect policy kept, got %v", policy)
+	}
+	free := &FreePolicy{}
+	if policy := HostFunctionPolicy(free); policy != free {
+		t.Errorf("Expect policy kept, got %v", policy)
+	}
+}
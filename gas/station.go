@@ -32,4 +32,5 @@ type Token interface {
 type App interface {
 	SetGasStation(gasStation Station)
 	GetGasContractToken() Token
+	GetMinGasPrice() uint32
 }
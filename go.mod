@@ -4,6 +4,7 @@ go 1.15
 
 require (
 	github.com/QuoineFinancial/liquid-chain-rlp v0.0.0-20200625105300-8a3d0c290807
+	github.com/btcsuite/btcd v0.21.0-beta
 	github.com/google/go-cmp v0.5.2
 	github.com/google/uuid v1.1.2
 	github.com/gorilla/mux v1.8.0
@@ -11,10 +12,13 @@ require (
 	github.com/linxGnu/grocksdb v1.6.25
 	github.com/pkg/errors v0.9.1
 	github.com/rs/cors v1.7.0
-	github.com/spf13/cobra v1.0.0
+	github.com/spf13/cobra v1.1.1
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.6.1
-	github.com/tendermint/tendermint v0.33.8
+	github.com/syndtr/goleveldb v1.0.1-0.20200815110645-5c35d600f0ca
+	github.com/tendermint/tendermint v0.34.0
 	github.com/vertexdlt/vertexvm v0.0.0-20201113091753-272c4d87302a
-	golang.org/x/crypto v0.0.0-20201012173705-84dcc777aaee
+	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897
 )
+
+replace github.com/vertexdlt/vertexvm => ./third_party/vertexvm
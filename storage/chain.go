@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/QuoineFinancial/liquid-chain/common"
@@ -9,6 +11,11 @@ import (
 	"github.com/QuoineFinancial/liquid-chain/trie"
 )
 
+var (
+	// ErrBlockNotFound used when block hash not found in chain
+	ErrBlockNotFound = errors.New("block not found")
+)
+
 // ChainStorage is storage for block
 type ChainStorage struct {
 	db.Database
@@ -72,6 +79,52 @@ func (bs *ChainStorage) Commit(stateRoot common.Hash) common.Hash {
 	return hash
 }
 
+// ImportBlock stores block with its transactions and receipts, whose tries must have roots of block
+func (bs *ChainStorage) ImportBlock(block *crypto.Block, txs []*crypto.Transaction, receipts []*crypto.Receipt) error {
+	txTrie, err := trie.New(common.EmptyHash, bs.Database)
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		rawTx, err := tx.Encode()
+		if err != nil {
+			return err
+		}
+		txTrie.Update(tx.Hash().Bytes(), rawTx)
+	}
+	if txRoot, err := txTrie.Commit(); err != nil {
+		return err
+	} else if txRoot != block.TransactionRoot {
+		return fmt.Errorf("Transaction root mismatch, expected %s, got %s", block.TransactionRoot, txRoot)
+	}
+
+	receiptTrie, err := trie.New(common.EmptyHash, bs.Database)
+	if err != nil {
+		return err
+	}
+	for _, receipt := range receipts {
+		rawReceipt, err := receipt.Encode()
+		if err != nil {
+			return err
+		}
+		receiptTrie.Update(receipt.Hash().Bytes(), rawReceipt)
+	}
+	if receiptRoot, err := receiptTrie.Commit(); err != nil {
+		return err
+	} else if receiptRoot != block.ReceiptRoot {
+		return fmt.Errorf("Receipt root mismatch, expected %s, got %s", block.ReceiptRoot, receiptRoot)
+	}
+
+	rawBlock, err := block.Encode()
+	if err != nil {
+		return err
+	}
+	bs.Put(block.Hash().Bytes(), rawBlock)
+	block.AddTransactions(txs...)
+	block.AddReceipts(receipts...)
+	return nil
+}
+
 // AddTransactionWithReceipt add tx and receipt to currentBlock
 func (bs *ChainStorage) AddTransactionWithReceipt(tx *crypto.Transaction, receipt *crypto.Receipt) error {
 	if bs.CurrentBlock == nil {
@@ -102,6 +155,9 @@ func (bs *ChainStorage) GetBlock(hash common.Hash) (*crypto.Block, error) {
 		return &crypto.GenesisBlock, nil
 	}
 	rawBlock := bs.Get(hash.Bytes())
+	if rawBlock == nil {
+		return nil, ErrBlockNotFound
+	}
 	return crypto.DecodeBlock(rawBlock)
 }
 
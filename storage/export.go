@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/trie"
+)
+
+// StateExportVersion is the format version of exported state
+const StateExportVersion = uint16(1)
+
+// exportStoragePageSize is maximum number of storage entries in a line of exported state
+const exportStoragePageSize = 1000
+
+// importCommitInterval is number of imported accounts and storage entries between two commits
+const importCommitInterval = 10000
+
+// StateExportHeader is the first line of exported state.
+// Each following line is an ExportedAccount
+type StateExportHeader struct {
+	Version   uint16          `json:"version"`
+	ChainID   string          `json:"chainId"`
+	AppState  json.RawMessage `json:"appState,omitempty"`
+	Height    uint64          `json:"height"`
+	BlockHash string          `json:"blockHash"`
+	StateRoot string          `json:"stateRoot"`
+
+	// Block of state with its transactions and receipts, hex encoded, lets importing node serve the block
+	Block        string   `json:"block"`
+	Transactions []string `json:"transactions,omitempty"`
+	Receipts     []string `json:"receipts,omitempty"`
+}
+
+// ExportedAccount is an account with its contract and storage.
+// Contract and Storage are hex encoded. Storage of more than exportStoragePageSize entries is split into pages,
+// the following lines with the same Address and only Storage continue storage of the account
+type ExportedAccount struct {
+	Address  string            `json:"address"`
+	Nonce    uint64            `json:"nonce"`
+	Creator  string            `json:"creator,omitempty"`
+	Admin    string            `json:"admin,omitempty"`
+	Contract string            `json:"contract,omitempty"`
+	Storage  map[string]string `json:"storage,omitempty"`
+}
+
+// ExportState writes header and all accounts of loaded state as JSON lines
+func (state *StateStorage) ExportState(writer io.Writer, header StateExportHeader) error {
+	encoder := json.NewEncoder(writer)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+
+	iterator := trie.NewIterator(state.stateTrie.NodeIterator(nil))
+	for iterator.Next() {
+		address, err := crypto.AddressFromBytes(iterator.Key)
+		if err != nil {
+			return err
+		}
+		account, err := state.GetAccount(address)
+		if err != nil {
+			return err
+		}
+		if err := account.export(encoder); err != nil {
+			return err
+		}
+	}
+	return iterator.Err
+}
+
+// export writes account with the first page of its storage, then the remaining pages
+func (account *Account) export(encoder *json.Encoder) error {
+	exported := &ExportedAccount{
+		Address: account.address.String(),
+		Nonce:   account.Nonce,
+	}
+	if account.Creator != crypto.EmptyAddress {
+		exported.Creator = account.Creator.String()
+	}
+	if admin := account.GetAdmin(); admin != crypto.EmptyAddress {
+		exported.Admin = admin.String()
+	}
+	if account.IsContract() {
+		exported.Contract = hex.EncodeToString(account.contract)
+	}
+
+	iterator := trie.NewIterator(account.storage.NodeIterator(nil))
+	for iterator.Next() {
+		if len(exported.Storage) == exportStoragePageSize {
+			if err := encoder.Encode(exported); err != nil {
+				return err
+			}
+			exported = &ExportedAccount{Address: exported.Address}
+		}
+		if exported.Storage == nil {
+			exported.Storage = make(map[string]string)
+		}
+		exported.Storage[hex.EncodeToString(iterator.Key)] = hex.EncodeToString(iterator.Value)
+	}
+	if iterator.Err != nil {
+		return iterator.Err
+	}
+	return encoder.Encode(exported)
+}
+
+// ImportState creates accounts read from exported state on an empty state,
+// then commits and verifies the state root against header.
+// Imported accounts are committed and flushed every importCommitInterval accounts and storage entries to keep memory bounded.
+// The returned header is nil if reader has no valid header
+func (state *StateStorage) ImportState(reader io.Reader, flush func() error) (*StateExportHeader, error) {
+	decoder := json.NewDecoder(bufio.NewReader(reader))
+	var header StateExportHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("Invalid state header: %v", err)
+	}
+	if header.Version != StateExportVersion {
+		return nil, fmt.Errorf("Unsupported state version %d", header.Version)
+	}
+
+	if err := state.LoadState(&crypto.GenesisBlock); err != nil {
+		return nil, err
+	}
+	previous, imported := "", 0
+	for {
+		var exported ExportedAccount
+		if err := decoder.Decode(&exported); err == io.EOF {
+			break
+		} else if err != nil {
+			return &header, fmt.Errorf("Invalid account: %v", err)
+		}
+		var err error
+		if exported.Address == previous {
+			err = state.importStoragePage(&exported)
+		} else {
+			err = state.importAccount(&exported)
+		}
+		if err != nil {
+			return &header, err
+		}
+		previous = exported.Address
+		if imported += 1 + len(exported.Storage); imported >= importCommitInterval {
+			state.Commit()
+			state.accounts = make(map[crypto.Address]*Account)
+			if err := flush(); err != nil {
+				return &header, err
+			}
+			imported = 0
+		}
+	}
+
+	if stateRoot := state.Commit(); stateRoot != common.HexToHash(header.StateRoot) {
+		return &header, fmt.Errorf("State root mismatch, expected %s, got %s", header.StateRoot, stateRoot)
+	}
+	return &header, nil
+}
+
+func (state *StateStorage) importAccount(exported *ExportedAccount) error {
+	address, err := crypto.AddressFromString(exported.Address)
+	if err != nil {
+		return fmt.Errorf("Invalid account address %s: %v", exported.Address, err)
+	}
+	creator := crypto.EmptyAddress
+	if len(exported.Creator) > 0 {
+		if creator, err = crypto.AddressFromString(exported.Creator); err != nil {
+			return fmt.Errorf("Invalid creator of account %s: %v", exported.Address, err)
+		}
+	}
+	admin := crypto.EmptyAddress
+	if len(exported.Admin) > 0 {
+		if admin, err = crypto.AddressFromString(exported.Admin); err != nil {
+			return fmt.Errorf("Invalid admin of account %s: %v", exported.Address, err)
+		}
+	}
+	contract, err := hex.DecodeString(exported.Contract)
+	if err != nil {
+		return fmt.Errorf("Invalid contract of account %s", exported.Address)
+	}
+
+	account, err := state.CreateAccount(creator, address, contract)
+	if err != nil {
+		return err
+	}
+	account.SetNonce(exported.Nonce)
+	if admin != crypto.EmptyAddress {
+		account.SetAdmin(admin)
+	}
+	return account.importStorage(exported)
+}
+
+// importStoragePage imports a page of storage of the account imported before
+func (state *StateStorage) importStoragePage(exported *ExportedAccount) error {
+	if exported.Nonce != 0 || len(exported.Creator) > 0 || len(exported.Admin) > 0 || len(exported.Contract) > 0 {
+		return fmt.Errorf("Invalid storage page of account %s", exported.Address)
+	}
+	address, err := crypto.AddressFromString(exported.Address)
+	if err != nil {
+		return fmt.Errorf("Invalid account address %s: %v", exported.Address, err)
+	}
+	// Account is loaded again if it was committed and released by a previous page
+	account, err := state.LoadAccount(address)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return fmt.Errorf("Storage page of missing account %s", exported.Address)
+	}
+	return account.importStorage(exported)
+}
+
+func (account *Account) importStorage(exported *ExportedAccount) error {
+	for key, value := range exported.Storage {
+		decodedKey, err := hex.DecodeString(key)
+		if err != nil {
+			return fmt.Errorf("Invalid storage key %s of account %s", key, exported.Address)
+		}
+		decodedValue, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("Invalid storage value of key %s of account %s", key, exported.Address)
+		}
+		if err := account.SetStorage(decodedKey, decodedValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
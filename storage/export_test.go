@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/db"
+)
+
+func TestExportImportStoragePages(t *testing.T) {
+	state := NewStateStorage(db.NewMemoryDB())
+	if err := state.LoadState(&crypto.GenesisBlock); err != nil {
+		t.Fatal(err)
+	}
+	address, _ := crypto.AddressFromString("LCR57ROUHIQ2AV4D3E3D7ZBTR6YXMKZQWTI4KSHSWCUCRXBKNJKKBCNY")
+	account, _ := state.CreateAccount(crypto.EmptyAddress, address, nil)
+	// Storage is larger than a page and than commit interval of import
+	entries := importCommitInterval + exportStoragePageSize/2
+	for i := 0; i < entries; i++ {
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, uint64(i))
+		if err := account.SetStorage(key, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stateRoot := state.Commit()
+
+	var exported bytes.Buffer
+	if err := state.ExportState(&exported, StateExportHeader{Version: StateExportVersion, StateRoot: stateRoot.String()}); err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(exported.Bytes()))
+	scanner.Buffer(nil, 1<<20)
+	scanner.Scan()
+	lines := 0
+	for ; scanner.Scan(); lines++ {
+		var page ExportedAccount
+		if err := json.Unmarshal(scanner.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		if page.Address != address.String() || len(page.Storage) > exportStoragePageSize {
+			t.Fatalf("Expect storage pages of %s with at most %d entries, got %d entries of %s", address, exportStoragePageSize, len(page.Storage), page.Address)
+		}
+	}
+	if want := (entries + exportStoragePageSize - 1) / exportStoragePageSize; lines != want {
+		t.Errorf("Expect %d lines of account, got %d", want, lines)
+	}
+
+	flushes := 0
+	imported := NewStateStorage(db.NewMemoryDB())
+	if _, err := imported.ImportState(bytes.NewReader(exported.Bytes()), func() error { flushes++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if flushes == 0 {
+		t.Errorf("Expect state flushed while importing storage of account")
+	}
+
+	// Pages carry storage only
+	accountLine := bytes.Index(exported.Bytes(), []byte(`"nonce":0`))
+	page := exported.Bytes()[accountLine+1:]
+	tampered := append(append([]byte{}, exported.Bytes()[:accountLine+1]...), bytes.Replace(page, []byte(`"nonce":0`), []byte(`"nonce":1`), 1)...)
+	if _, err := NewStateStorage(db.NewMemoryDB()).ImportState(bytes.NewReader(tampered), func() error { return nil }); err == nil || err.Error() != "Invalid storage page of account "+address.String() {
+		t.Errorf("Expect page with account fields rejected, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 
@@ -24,9 +25,24 @@ func NewMetaStorage(db db.Database) *MetaStorage {
 	return &MetaStorage{db}
 }
 
-// StoreBlockMetas extracts all indexes and store it
-func (ms *MetaStorage) StoreBlockMetas(block *crypto.Block) error {
-	ms.Put(
+// ChainMetas are metas of chain from genesis, stored once along with metas of a block
+type ChainMetas struct {
+	ChainID         string
+	GenesisAppState []byte
+}
+
+// ContractUpgrade is upgrade of contract by a transaction, indexed along with metas of block of the transaction
+type ContractUpgrade struct {
+	Contract    crypto.Address
+	Transaction common.Hash
+}
+
+// StoreBlockMetas extracts all indexes and store them in one batch with chain metas which are not stored yet.
+// Upgrades are given by chain since events of receipts might be emitted by any contract
+func (ms *MetaStorage) StoreBlockMetas(block *crypto.Block, chainMetas ChainMetas, upgrades []ContractUpgrade) error {
+	batch := ms.NewBatch()
+	ms.putChainMetas(batch, chainMetas)
+	batch.Put(
 		ms.encodeBlockHeightToBlockHashKey(block.Height),
 		block.Hash().Bytes(),
 	)
@@ -34,27 +50,71 @@ func (ms *MetaStorage) StoreBlockMetas(block *crypto.Block) error {
 	blockHeightByte := make([]byte, 8)
 	binary.LittleEndian.PutUint64(blockHeightByte, block.Height)
 	for _, tx := range block.Transactions() {
-		ms.Put(
+		batch.Put(
 			ms.encodeTxHashToBlockHeightKey(tx.Hash()),
 			blockHeightByte,
 		)
 	}
 
+	upgradedContracts := make(map[common.Hash]crypto.Address)
+	for _, upgrade := range upgrades {
+		upgradedContracts[upgrade.Transaction] = upgrade.Contract
+	}
 	for _, receipt := range block.Receipts() {
-		ms.Put(
+		batch.Put(
 			ms.encodeTxHashToReceiptHashKey(receipt.Transaction),
 			receipt.Hash().Bytes(),
 		)
+		if contract, ok := upgradedContracts[receipt.Transaction]; ok {
+			batch.Put(
+				ms.encodeContractUpgradeKey(contract, block.Height, receipt.Index),
+				receipt.Transaction.Bytes(),
+			)
+		}
 	}
 
 	if block.Height > ms.LatestBlockHeight() {
-		ms.Put(
+		batch.Put(
 			ms.encodeLatestBlockHeightKey(),
 			blockHeightByte,
 		)
 	}
 
-	return nil
+	return batch.Write()
+}
+
+// StoreChainMetas stores chain metas which are not stored yet in one batch
+func (ms *MetaStorage) StoreChainMetas(chainMetas ChainMetas) error {
+	batch := ms.NewBatch()
+	ms.putChainMetas(batch, chainMetas)
+	return batch.Write()
+}
+
+func (ms *MetaStorage) putChainMetas(batch db.Batch, chainMetas ChainMetas) {
+	if len(chainMetas.ChainID) > 0 && chainMetas.ChainID != ms.ChainID() {
+		batch.Put(ms.encodeChainIDKey(), []byte(chainMetas.ChainID))
+	}
+	if len(chainMetas.GenesisAppState) > 0 && !bytes.Equal(chainMetas.GenesisAppState, ms.GenesisAppState()) {
+		batch.Put(ms.encodeGenesisAppStateKey(), chainMetas.GenesisAppState)
+	}
+}
+
+// ChainMetas retrieves stored chain metas, empty if chain is not initialized
+func (ms *MetaStorage) ChainMetas() ChainMetas {
+	return ChainMetas{ChainID: ms.ChainID(), GenesisAppState: ms.GenesisAppState()}
+}
+
+// RollbackLatestBlockHeight sets latest block height back to height
+// and removes block hash indexes of later heights
+func (ms *MetaStorage) RollbackLatestBlockHeight(height uint64) error {
+	batch := ms.NewBatch()
+	for h := ms.LatestBlockHeight(); h > height; h-- {
+		batch.Delete(ms.encodeBlockHeightToBlockHashKey(h))
+	}
+	blockHeightByte := make([]byte, 8)
+	binary.LittleEndian.PutUint64(blockHeightByte, height)
+	batch.Put(ms.encodeLatestBlockHeightKey(), blockHeightByte)
+	return batch.Write()
 }
 
 // LatestBlockHeight retrieves latest block height
@@ -86,3 +146,48 @@ func (ms *MetaStorage) TxHashToReceiptHash(txHash common.Hash) common.Hash {
 	receiptHashBytes := ms.Get(ms.encodeTxHashToReceiptHashKey(txHash))
 	return common.BytesToHash(receiptHashBytes)
 }
+
+// ContractUpgrades retrieves hashes of transactions which upgraded contract at address, oldest first
+func (ms *MetaStorage) ContractUpgrades(address crypto.Address) []common.Hash {
+	iterator := db.NewPrefixIterator(ms, ms.encodeContractUpgradePrefix(address))
+	defer iterator.Release()
+	hashes := []common.Hash{}
+	for iterator.Next() {
+		hashes = append(hashes, common.BytesToHash(iterator.Value()))
+	}
+	return hashes
+}
+
+// ChainID retrieves the chain ID, empty if chain is not initialized
+func (ms *MetaStorage) ChainID() string {
+	return string(ms.Get(ms.encodeChainIDKey()))
+}
+
+// GenesisAppState retrieves the encoded app state, empty if chain is not initialized
+func (ms *MetaStorage) GenesisAppState() []byte {
+	return ms.Get(ms.encodeGenesisAppStateKey())
+}
+
+// StoreEarliestStateHeight stores the lowest height whose state is not pruned
+func (ms *MetaStorage) StoreEarliestStateHeight(height uint64) {
+	blockHeightByte := make([]byte, 8)
+	binary.LittleEndian.PutUint64(blockHeightByte, height)
+	ms.Put(ms.encodeEarliestStateHeightKey(), blockHeightByte)
+}
+
+// EarliestStateHeight retrieves the lowest height whose state is not pruned
+func (ms *MetaStorage) EarliestStateHeight() uint64 {
+	blockHeightByte := ms.Get(ms.encodeEarliestStateHeightKey())
+	if len(blockHeightByte) == 0 {
+		return crypto.GenesisBlock.Height
+	}
+	return binary.LittleEndian.Uint64(blockHeightByte)
+}
+
+// CheckStateHeight returns ErrStatePruned if state at height was pruned
+func (ms *MetaStorage) CheckStateHeight(height uint64) error {
+	if height < ms.EarliestStateHeight() {
+		return ErrStatePruned
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 
 	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
 )
 
 const (
@@ -11,6 +12,10 @@ const (
 	txHashToBlockHeightPrefix    byte = 0x1
 	latestBlockHeightPrefix      byte = 0x2
 	txHashToReceiptHashPrefix    byte = 0x3
+	chainIDPrefix                byte = 0x4
+	genesisAppStatePrefix        byte = 0x5
+	earliestStateHeightPrefix    byte = 0x6
+	contractUpgradePrefix        byte = 0x7
 )
 
 func (index *MetaStorage) encodeTxHashToReceiptHashKey(hash common.Hash) []byte {
@@ -31,6 +36,30 @@ func (index *MetaStorage) encodeLatestBlockHeightKey() []byte {
 	return index.encodeKey(latestBlockHeightPrefix, []byte{})
 }
 
+func (index *MetaStorage) encodeChainIDKey() []byte {
+	return index.encodeKey(chainIDPrefix, []byte{})
+}
+
+func (index *MetaStorage) encodeGenesisAppStateKey() []byte {
+	return index.encodeKey(genesisAppStatePrefix, []byte{})
+}
+
+func (index *MetaStorage) encodeEarliestStateHeightKey() []byte {
+	return index.encodeKey(earliestStateHeightPrefix, []byte{})
+}
+
+// encodeContractUpgradeKey orders upgrades of contract by block height and receipt index
+func (index *MetaStorage) encodeContractUpgradeKey(address crypto.Address, height uint64, receiptIndex uint32) []byte {
+	key := make([]byte, 12)
+	binary.BigEndian.PutUint64(key, height)
+	binary.BigEndian.PutUint32(key[8:], receiptIndex)
+	return index.encodeKey(contractUpgradePrefix, append(address[:], key...))
+}
+
+func (index *MetaStorage) encodeContractUpgradePrefix(address crypto.Address) []byte {
+	return index.encodeKey(contractUpgradePrefix, address[:])
+}
+
 func (index *MetaStorage) encodeKey(prefix byte, key []byte) []byte {
 	return append([]byte{byte(prefix)}, key...)
 }
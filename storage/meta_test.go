@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/QuoineFinancial/liquid-chain/abi"
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/crypto"
+	"github.com/QuoineFinancial/liquid-chain/db"
+)
+
+func TestStoreContractUpgrades(t *testing.T) {
+	meta := NewMetaStorage(db.NewMemoryDB())
+	address, _ := crypto.AddressFromString("LCR57ROUHIQ2AV4D3E3D7ZBTR6YXMKZQWTI4KSHSWCUCRXBKNJKKBCNY")
+	upgradeEvent := &crypto.Event{ID: abi.UpgradeEvent.ID(), Contract: address}
+	upgradeTx := common.BytesToHash([]byte("upgrade"))
+	forgedTx := common.BytesToHash([]byte("forged"))
+
+	block := &crypto.Block{Height: 1}
+	block.AddReceipts(
+		&crypto.Receipt{Transaction: forgedTx, Index: 0, Events: []*crypto.Event{upgradeEvent}},
+		&crypto.Receipt{Transaction: upgradeTx, Index: 1, Events: []*crypto.Event{upgradeEvent}},
+	)
+	upgrades := []ContractUpgrade{{Contract: address, Transaction: upgradeTx}}
+	if err := meta.StoreBlockMetas(block, ChainMetas{}, upgrades); err != nil {
+		t.Fatal(err)
+	}
+
+	// Upgrade event emitted by a contract is not indexed
+	hashes := meta.ContractUpgrades(address)
+	if len(hashes) != 1 || hashes[0] != upgradeTx {
+		t.Errorf("Expect upgrade %v indexed, got %v", upgradeTx, hashes)
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/db"
+	"github.com/QuoineFinancial/liquid-chain/trie"
+)
+
+var (
+	// ErrStatePruned used when state of requested height was removed by pruning
+	ErrStatePruned = errors.New("state pruned")
+)
+
+// stateMarker collects keys reachable from state roots.
+// State and storage trie nodes are tracked apart since only state trie leaves are accounts
+type stateMarker struct {
+	database     db.Database
+	stateNodes   map[common.Hash]struct{}
+	storageNodes map[common.Hash]struct{}
+	contracts    map[common.Hash]struct{}
+}
+
+// StatePruning removes trie nodes and contracts which are not reachable from any of retained state roots.
+// Keys are collected from a snapshot while states are still committed, so roots of states committed
+// after collecting must be retained again before sweeping
+type StatePruning struct {
+	marker     *stateMarker
+	candidates [][]byte
+}
+
+// NewStatePruning returns a pruning of states in database
+func NewStatePruning(database db.Database) *StatePruning {
+	return &StatePruning{
+		marker: &stateMarker{
+			database:     database,
+			stateNodes:   make(map[common.Hash]struct{}),
+			storageNodes: make(map[common.Hash]struct{}),
+			contracts:    make(map[common.Hash]struct{}),
+		},
+	}
+}
+
+// Retain marks nodes and contracts of states at roots
+func (pruning *StatePruning) Retain(roots []common.Hash) error {
+	for _, root := range roots {
+		if err := pruning.marker.markTrie(root, pruning.marker.stateNodes, pruning.marker.markAccount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Collect collects keys which are not retained from a snapshot of database
+func (pruning *StatePruning) Collect() {
+	snapshot := pruning.marker.database.NewSnapshot()
+	defer snapshot.Release()
+	iterator := snapshot.NewIterator(nil, nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		// Keys which are not hashes are not written by state, leave them
+		if len(iterator.Key()) != common.HashLength || pruning.marker.isMarked(common.BytesToHash(iterator.Key())) {
+			continue
+		}
+		pruning.candidates = append(pruning.candidates, append([]byte{}, iterator.Key()...))
+	}
+}
+
+// Sweep removes collected keys which are still not retained, it returns number of removed keys
+func (pruning *StatePruning) Sweep() (int, error) {
+	removed := 0
+	batch := pruning.marker.database.NewBatch()
+	for _, key := range pruning.candidates {
+		if pruning.marker.isMarked(common.BytesToHash(key)) {
+			continue
+		}
+		batch.Delete(key)
+		removed++
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	pruning.candidates = nil
+	return removed, nil
+}
+
+func (marker *stateMarker) isMarked(hash common.Hash) bool {
+	_, isStateNode := marker.stateNodes[hash]
+	_, isStorageNode := marker.storageNodes[hash]
+	_, isContract := marker.contracts[hash]
+	return isStateNode || isStorageNode || isContract
+}
+
+// markTrie marks nodes of trie at root, subtrees marked before are skipped
+func (marker *stateMarker) markTrie(root common.Hash, marked map[common.Hash]struct{}, onLeaf func([]byte) error) error {
+	if _, ok := marked[root]; ok {
+		return nil
+	}
+	tree, err := trie.New(root, marker.database)
+	if err != nil {
+		return err
+	}
+
+	iterator := tree.NodeIterator(nil)
+	descend := true
+	for iterator.Next(descend) {
+		descend = true
+		if hash := iterator.Hash(); hash != common.EmptyHash {
+			if _, ok := marked[hash]; ok {
+				descend = false
+				continue
+			}
+			marked[hash] = struct{}{}
+		}
+		if iterator.Leaf() && onLeaf != nil {
+			if err := onLeaf(iterator.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+
+	// Root is stored even when it is empty or smaller than a hash
+	marked[root] = struct{}{}
+	return nil
+}
+
+func (marker *stateMarker) markAccount(raw []byte) error {
+	var account Account
+	if err := rlp.DecodeBytes(raw, &account); err != nil {
+		return err
+	}
+	if account.IsContract() {
+		marker.contracts[account.ContractHash] = struct{}{}
+	}
+	return marker.markTrie(account.StorageHash, marker.storageNodes, nil)
+}
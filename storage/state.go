@@ -15,6 +15,14 @@ type StateStorage struct {
 	stateTrie         *trie.Trie
 	accounts          map[crypto.Address]*Account
 	accountCheckpoint common.Hash
+	snapshots         []*stateSnapshot
+}
+
+// stateSnapshot keeps loaded accounts with copies of their content at a call frame
+type stateSnapshot struct {
+	stateTrie *trie.Trie
+	accounts  map[crypto.Address]*Account
+	copies    map[crypto.Address]Account
 }
 
 // NewStateStorage returns a state storage
@@ -40,6 +48,7 @@ func (state *StateStorage) LoadState(block *crypto.Block) error {
 	state.stateTrie = stateTrie
 	state.accountCheckpoint = block.StateRoot
 	state.accounts = make(map[crypto.Address]*Account)
+	state.snapshots = nil
 
 	return nil
 }
@@ -49,6 +58,11 @@ func (state *StateStorage) GetBlock() *crypto.Block {
 	return state.block
 }
 
+// ProveAccount returns merkle proof of the account at address in state trie
+func (state *StateStorage) ProveAccount(address crypto.Address) ([][]byte, error) {
+	return state.stateTrie.Prove(address[:])
+}
+
 // Hash retrives hash of entire state
 func (state *StateStorage) Hash() common.Hash {
 	var err error
@@ -106,6 +120,7 @@ func (state *StateStorage) Commit() common.Hash {
 	}
 
 	state.accountCheckpoint = stateRootHash
+	state.snapshots = nil
 	return stateRootHash
 }
 
@@ -117,4 +132,42 @@ func (state *StateStorage) Revert() {
 	}
 	state.stateTrie = t
 	state.accounts = make(map[crypto.Address]*Account)
+	state.snapshots = nil
+}
+
+// Snapshot saves loaded accounts for a nested call frame and returns its id.
+// Snapshots are nested, reverting or discarding one also drops snapshots taken after it
+func (state *StateStorage) Snapshot() int {
+	snapshot := &stateSnapshot{
+		stateTrie: state.stateTrie.Copy(),
+		accounts:  make(map[crypto.Address]*Account, len(state.accounts)),
+		copies:    make(map[crypto.Address]Account, len(state.accounts)),
+	}
+	for address, account := range state.accounts {
+		snapshot.accounts[address] = account
+		if account != nil {
+			snapshot.copies[address] = account.copy()
+		}
+	}
+	state.snapshots = append(state.snapshots, snapshot)
+	return len(state.snapshots) - 1
+}
+
+// RevertToSnapshot restores accounts to snapshot with id.
+// Accounts are restored in place so references held by outer call frames stay valid
+func (state *StateStorage) RevertToSnapshot(id int) {
+	snapshot := state.snapshots[id]
+	for address, account := range snapshot.accounts {
+		if account != nil {
+			*account = snapshot.copies[address]
+		}
+	}
+	state.stateTrie = snapshot.stateTrie
+	state.accounts = snapshot.accounts
+	state.snapshots = state.snapshots[:id]
+}
+
+// DiscardSnapshot keeps changes made after snapshot with id and drops the snapshot
+func (state *StateStorage) DiscardSnapshot(id int) {
+	state.snapshots = state.snapshots[:id]
 }
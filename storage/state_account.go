@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"bytes"
+
 	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
 	"github.com/QuoineFinancial/liquid-chain/abi"
 	"github.com/QuoineFinancial/liquid-chain/common"
@@ -15,6 +17,8 @@ type Account struct {
 	ContractHash common.Hash    `json:"contractHash"`
 	StorageHash  common.Hash    `json:"storageHash"`
 	Creator      crypto.Address `json:"creator"`
+	// Admin may upgrade contract besides creator, empty if contract has no admin
+	Admin []crypto.Address `json:"admin,omitempty" rlp:"tail"`
 
 	dirty    bool
 	address  crypto.Address
@@ -95,6 +99,58 @@ func (account *Account) SetStorage(key, value []byte) error {
 	return account.storage.Update(key, value)
 }
 
+// DeleteStorage removes key from the account storage
+func (account *Account) DeleteStorage(key []byte) error {
+	account.dirty = true
+	return account.storage.Delete(key)
+}
+
+// StorageIterator iterates key-value pairs of account storage with a key prefix
+type StorageIterator struct {
+	iterator *trie.Iterator
+	prefix   []byte
+	done     bool
+}
+
+// NewStorageIterator returns an iterator over storage keys with prefix in key order.
+// Storage is iterated as of the iterator creation
+func (account *Account) NewStorageIterator(prefix []byte) *StorageIterator {
+	return &StorageIterator{
+		iterator: trie.NewIterator(account.storage.NodeIterator(prefix)),
+		prefix:   append([]byte{}, prefix...),
+	}
+}
+
+// Next moves to the next key-value pair, returns false when iteration is done
+func (it *StorageIterator) Next() bool {
+	if !it.done && it.iterator.Next() && bytes.HasPrefix(it.iterator.Key, it.prefix) {
+		return true
+	}
+	it.done = true
+	it.iterator.Key, it.iterator.Value = nil, nil
+	return false
+}
+
+// Key returns the current key
+func (it *StorageIterator) Key() []byte {
+	return it.iterator.Key
+}
+
+// Value returns the current value
+func (it *StorageIterator) Value() []byte {
+	return it.iterator.Value
+}
+
+// Err returns the error of iteration
+func (it *StorageIterator) Err() error {
+	return it.iterator.Err
+}
+
+// ProveStorage returns merkle proof of the value at key of storage
+func (account *Account) ProveStorage(key []byte) ([][]byte, error) {
+	return account.storage.Prove(key)
+}
+
 // GetAddress returns state address
 func (account *Account) GetAddress() crypto.Address {
 	return account.address
@@ -110,6 +166,11 @@ func (account *Account) GetContract() (*abi.Contract, error) {
 	return abi.DecodeContract(account.contract)
 }
 
+// GetContractBytes retrieves encoded contract of account state
+func (account *Account) GetContractBytes() []byte {
+	return account.contract
+}
+
 // SetNonce stores the latest nonce to account state
 func (account *Account) SetNonce(nonce uint64) {
 	account.dirty = true
@@ -121,6 +182,42 @@ func (account *Account) GetCreator() crypto.Address {
 	return account.Creator
 }
 
+// GetAdmin returns admin of contract, empty address if there is none
+func (account *Account) GetAdmin() crypto.Address {
+	if len(account.Admin) == 0 {
+		return crypto.EmptyAddress
+	}
+	return account.Admin[0]
+}
+
+// SetAdmin designates admin of contract, empty address removes admin
+func (account *Account) SetAdmin(admin crypto.Address) {
+	account.dirty = true
+	account.Admin = nil
+	if admin != crypto.EmptyAddress {
+		account.Admin = []crypto.Address{admin}
+	}
+}
+
+// CanUpgrade checks whether address is creator or admin of contract
+func (account *Account) CanUpgrade(address crypto.Address) bool {
+	if address == crypto.EmptyAddress {
+		return false
+	}
+	return address == account.Creator || address == account.GetAdmin()
+}
+
+// SetContract replaces contract of account, its storage is kept
+func (account *Account) SetContract(contract []byte) {
+	account.setContract(contract)
+}
+
+func (account *Account) copy() Account {
+	copy := *account
+	copy.storage = account.storage.Copy()
+	return copy
+}
+
 func (account *Account) setContract(contract []byte) {
 	account.dirty = true
 	account.contract = contract
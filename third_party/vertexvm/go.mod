@@ -0,0 +1,3 @@
+module github.com/vertexdlt/vertexvm
+
+go 1.12
@@ -17,7 +17,7 @@ type Token struct {
 }
 
 func (token *Token) invokeContract(caller crypto.Address, method string, args []string) (uint64, []*crypto.Event, error) {
-	contract, err := token.account.GetContract()
+	contract, err := engine.LoadContract(token.account)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -173,6 +173,21 @@ func (h *hasher) store(node Node, db db.Database, force bool) (Node, error) {
 	return hash, nil
 }
 
+// proofHash is used to construct trie proofs, and returns the 'collapsed'
+// node (for later RLP encoding) as well as the hashed node -- unless the
+// node is smaller than 32 bytes, in which case it will be returned as is.
+func (h *hasher) proofHash(original Node) (Node, Node, error) {
+	collapsed, _, err := h.hashChildren(original, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashed, err := h.store(collapsed, nil, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return collapsed, hashed, nil
+}
+
 func (h *hasher) makeHashNode() (hashNode, error) {
 	h.blake.Reset()
 	_, err := h.blake.Write(h.buf)
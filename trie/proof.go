@@ -0,0 +1,149 @@
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/QuoineFinancial/liquid-chain-rlp/rlp"
+	"github.com/QuoineFinancial/liquid-chain/common"
+)
+
+// Prove constructs a merkle proof for key. The result contains all encoded nodes
+// on the path to the value at key. The value itself is also included in the last
+// node and can be retrieved by verifying the proof.
+//
+// If the trie does not contain a value for key, the returned proof contains all
+// nodes of the longest existing prefix of the key (at least the root node), ending
+// with the node that proves the absence of the key.
+func (tree *Trie) Prove(key []byte) ([][]byte, error) {
+	// Collect all nodes on the path to key.
+	key = keybytesToHex(key)
+	var nodes []Node
+	currentNode := tree.root
+	for len(key) > 0 && currentNode != nil {
+		switch node := currentNode.(type) {
+		case *shortNode:
+			if len(key) < len(node.Key) || !bytes.Equal(node.Key, key[:len(node.Key)]) {
+				// The trie doesn't contain the key.
+				currentNode = nil
+			} else {
+				currentNode = node.Value
+				key = key[len(node.Key):]
+			}
+			nodes = append(nodes, node)
+		case *branchNode:
+			currentNode = node.Children[key[0]]
+			key = key[1:]
+			nodes = append(nodes, node)
+		case hashNode:
+			loadedNode, err := tree.loadNode(node)
+			if err != nil {
+				return nil, err
+			}
+			currentNode = loadedNode
+		case valueNode:
+			currentNode = nil
+		default:
+			panic(fmt.Sprintf("%T: invalid node: %v", currentNode, currentNode))
+		}
+	}
+
+	hasher := newHasher()
+	defer returnHasherToPool(hasher)
+	proof := [][]byte{}
+	for i, node := range nodes {
+		collapsed, hashed, err := hasher.proofHash(node)
+		if err != nil {
+			return nil, err
+		}
+		// Nodes smaller than a hash are embedded in their parent, except the root
+		if _, ok := hashed.(hashNode); ok || i == 0 {
+			encoded, err := rlp.EncodeToBytes(collapsed)
+			if err != nil {
+				return nil, err
+			}
+			proof = append(proof, encoded)
+		}
+	}
+	return proof, nil
+}
+
+// VerifyProof checks merkle proofs. The given proof must contain the value for
+// key in a trie with the given root hash. VerifyProof returns an error if the
+// proof contains invalid trie nodes or the wrong value. A proof of absence
+// returns nil value without error.
+func VerifyProof(rootHash common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	if rootHash == common.EmptyHash || rootHash == emptyRoot {
+		if len(proof) > 0 {
+			return nil, errors.New("Unexpected proof nodes for empty trie")
+		}
+		return nil, nil
+	}
+
+	hasher := newHasher()
+	defer returnHasherToPool(hasher)
+	proofNodes := make(map[common.Hash][]byte)
+	for _, encoded := range proof {
+		hasher.buf.Reset()
+		hasher.buf.Write(encoded)
+		hash, err := hasher.makeHashNode()
+		if err != nil {
+			return nil, err
+		}
+		proofNodes[common.BytesToHash(hash)] = encoded
+	}
+
+	key = keybytesToHex(key)
+	wantHash := rootHash
+	for i := 0; ; i++ {
+		encoded, ok := proofNodes[wantHash]
+		if !ok {
+			return nil, fmt.Errorf("Proof node %d (hash %s) missing", i, wantHash.String())
+		}
+		node, err := decodeNode(wantHash.Bytes(), encoded)
+		if err != nil {
+			return nil, fmt.Errorf("Bad proof node %d: %v", i, err)
+		}
+		keyRest, child := getEmbedded(node, key)
+		switch child := child.(type) {
+		case nil:
+			// The trie doesn't contain the key.
+			return nil, nil
+		case hashNode:
+			key = keyRest
+			wantHash = common.BytesToHash(child)
+		case valueNode:
+			return child, nil
+		}
+	}
+}
+
+// getEmbedded follows key through node and its embedded children, stopping at
+// the first hash node, value node or missing child.
+func getEmbedded(node Node, key []byte) ([]byte, Node) {
+	for {
+		switch n := node.(type) {
+		case *shortNode:
+			if len(key) < len(n.Key) || !bytes.Equal(n.Key, key[:len(n.Key)]) {
+				return nil, nil
+			}
+			node = n.Value
+			key = key[len(n.Key):]
+		case *branchNode:
+			if len(key) == 0 {
+				return nil, nil
+			}
+			node = n.Children[key[0]]
+			key = key[1:]
+		case hashNode:
+			return key, n
+		case nil:
+			return key, nil
+		case valueNode:
+			return nil, n
+		default:
+			panic(fmt.Sprintf("%T: invalid node: %v", node, node))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/QuoineFinancial/liquid-chain/common"
+	"github.com/QuoineFinancial/liquid-chain/db"
+)
+
+func newProofTestTrie(t *testing.T) (*Trie, common.Hash, db.Database) {
+	database := db.NewMemoryDB()
+	tree, _ := New(common.Hash{}, database)
+	for _, node := range nodes {
+		if err := tree.Update(node.key, node.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := tree.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree, root, database
+}
+
+func TestProof(t *testing.T) {
+	tree, root, database := newProofTestTrie(t)
+	loadedTree, _ := New(root, database)
+	for _, tr := range []*Trie{tree, loadedTree} {
+		for _, node := range nodes {
+			proof, err := tr.Prove(node.key)
+			if err != nil {
+				t.Fatalf("prove error for key %x: %v", node.key, err)
+			}
+			value, err := VerifyProof(root, node.key, proof)
+			if err != nil {
+				t.Fatalf("verify error for key %x: %v", node.key, err)
+			}
+			if !bytes.Equal(value, node.value) {
+				t.Fatalf("verified value mismatch for key %x: have %x, want %x", node.key, value, node.value)
+			}
+		}
+	}
+}
+
+func TestUncommittedProof(t *testing.T) {
+	tree := newEmpty()
+	updateString(tree, "doe", "reindeer")
+	updateString(tree, "dog", "puppy")
+	updateString(tree, "dogglesworth", "cat")
+	root := tree.Hash()
+
+	for _, key := range []string{"doe", "dog", "dogglesworth"} {
+		proof, err := tree.Prove([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := VerifyProof(root, []byte(key), proof)
+		if err != nil {
+			t.Fatalf("verify error for key %s: %v", key, err)
+		}
+		if !bytes.Equal(value, getString(tree, key)) {
+			t.Errorf("verified value mismatch for key %s: have %s", key, value)
+		}
+	}
+}
+
+func TestMissingKeyProof(t *testing.T) {
+	tree, root, _ := newProofTestTrie(t)
+	for _, key := range []string{"a", "do", "dogg", "doggy", "zzzz"} {
+		proof, err := tree.Prove([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(proof) == 0 {
+			t.Errorf("proof of absence of %s is empty", key)
+		}
+		value, err := VerifyProof(root, []byte(key), proof)
+		if err != nil {
+			t.Fatalf("verify error for key %s: %v", key, err)
+		}
+		if value != nil {
+			t.Errorf("verified value for missing key %s: %x", key, value)
+		}
+	}
+}
+
+func TestBadProof(t *testing.T) {
+	tree, root, _ := newProofTestTrie(t)
+	key := []byte("dogglesworth")
+	proof, _ := tree.Prove(key)
+
+	// Missing node
+	if _, err := VerifyProof(root, key, proof[1:]); err == nil {
+		t.Error("expected error for proof without root node")
+	}
+
+	// Tampered node
+	tampered := make([][]byte, len(proof))
+	copy(tampered, proof)
+	last := append([]byte{}, proof[len(proof)-1]...)
+	last[len(last)-1] ^= 0xff
+	tampered[len(tampered)-1] = last
+	if value, err := VerifyProof(root, key, tampered); err == nil && bytes.Equal(value, []byte("cat")) {
+		t.Error("expected tampered proof to fail")
+	}
+
+	// Wrong root
+	if _, err := VerifyProof(common.HexToHash("01"), key, proof); err == nil {
+		t.Error("expected error for proof with wrong root")
+	}
+}
+
+func TestEmptyTrieProof(t *testing.T) {
+	tree := newEmpty()
+	proof, err := tree.Prove([]byte("dog"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof) != 0 {
+		t.Errorf("expected empty proof, got %d nodes", len(proof))
+	}
+	for _, root := range []common.Hash{common.EmptyHash, tree.Hash()} {
+		value, err := VerifyProof(root, []byte("dog"), proof)
+		if err != nil || value != nil {
+			t.Errorf("expected absence in empty trie, got value %x, error %v", value, err)
+		}
+	}
+}
@@ -145,15 +145,27 @@ func (tree *Trie) Update(key, value []byte) error {
 		}
 		tree.root = newRoot
 	} else {
-		_, newRoot, err := tree.delete(tree.root, hexKey)
-		if err != nil {
-			return err
-		}
-		tree.root = newRoot
+		return tree.Delete(key)
 	}
 	return nil
 }
 
+// Delete removes key from the trie, missing key is ignored
+func (tree *Trie) Delete(key []byte) error {
+	_, newRoot, err := tree.delete(tree.root, keybytesToHex(key))
+	if err != nil {
+		return err
+	}
+	tree.root = newRoot
+	return nil
+}
+
+// Copy returns a copy of the trie, nodes are shared since updates never modify them in place
+func (tree *Trie) Copy() *Trie {
+	copy := *tree
+	return &copy
+}
+
 // Hash returns the root hash
 func (tree *Trie) Hash() common.Hash {
 	hash, cached, _ := tree.hashRoot(nil)
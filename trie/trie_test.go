@@ -27,7 +27,7 @@ func getString(trie *Trie, k string) []byte {
 }
 
 func deleteString(trie *Trie, k string) {
-	err := trie.Update([]byte(k), nil)
+	err := trie.Delete([]byte(k))
 	if err != nil {
 		panic(err)
 	}
@@ -159,6 +159,46 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestDeleteMissingKey(t *testing.T) {
+	trie := newEmpty()
+	updateString(trie, "doge", "coin")
+	updateString(trie, "dog", "puppy")
+	hash := trie.Hash()
+
+	deleteString(trie, "do")
+	deleteString(trie, "horse")
+	if got := trie.Hash(); got != hash {
+		t.Errorf("expected %x got %x", hash, got)
+	}
+
+	deleteString(trie, "doge")
+	deleteString(trie, "dog")
+	if got := trie.Hash(); got != emptyRoot {
+		t.Errorf("expected %x got %x", emptyRoot, got)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	trie := newEmpty()
+	updateString(trie, "doe", "reindeer")
+	updateString(trie, "dog", "puppy")
+	hash := trie.Hash()
+
+	copied := trie.Copy()
+	updateString(trie, "dog", "doggy")
+	deleteString(trie, "doe")
+	updateString(trie, "dogglesworth", "cat")
+	if got := copied.Hash(); got != hash {
+		t.Errorf("expected %x got %x", hash, got)
+	}
+	if value := getString(copied, "dog"); string(value) != "puppy" {
+		t.Errorf("expected %s got %s", "puppy", value)
+	}
+	if value := getString(trie, "dog"); string(value) != "doggy" {
+		t.Errorf("expected %s got %s", "doggy", value)
+	}
+}
+
 func TestEmptyValues(t *testing.T) {
 	trie := newEmpty()
 